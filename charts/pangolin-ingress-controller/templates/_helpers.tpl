@@ -59,15 +59,6 @@ Create the name of the service account to use
 {{- end }}
 {{- end }}
 
-{{/*
-Create the tunnel class mapping as a multiline string
-*/}}
-{{- define "pangolin-ingress-controller.tunnelClassMapping" -}}
-{{- range $key, $value := .Values.config.tunnelClassMapping }}
-{{ $key }}={{ $value }}
-{{- end }}
-{{- end }}
-
 {{/*
 Image name
 */}}
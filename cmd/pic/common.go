@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
+)
+
+// newScheme returns a scheme with the built-in, Pangolin and PIC types registered.
+func newScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := pangolincrd.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := piccrd.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+// newClient builds a client for the cluster selected by kubeconfig and context.
+// An empty kubeconfig uses the standard loading rules ($KUBECONFIG, ~/.kube/config).
+func newClient(scheme *runtime.Scheme, kubeconfig, kubeContext string) (client.Client, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return ownerIndexClient{Client: c}, nil
+}
+
+// ownerIndexClient serves the controller's OwnerUIDIndex lookups, which
+// the API server cannot answer, by listing the namespace and matching the
+// owners as the index does.
+type ownerIndexClient struct {
+	client.Client
+}
+
+func (c ownerIndexClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	options := (&client.ListOptions{}).ApplyOptions(opts)
+	resources, ok := list.(*pangolincrd.PangolinResourceList)
+	if !ok || options.FieldSelector == nil {
+		return c.Client.List(ctx, list, opts...)
+	}
+	uid, indexed := options.FieldSelector.RequiresExactMatch(controller.OwnerUIDIndex)
+	if !indexed {
+		return c.Client.List(ctx, list, opts...)
+	}
+
+	options.FieldSelector = nil
+	if err := c.Client.List(ctx, resources, options); err != nil {
+		return err
+	}
+	owned := resources.Items[:0]
+	for _, resource := range resources.Items {
+		if slices.Contains(controller.IndexOwnerUID(&resource), uid) {
+			owned = append(owned, resource)
+		}
+	}
+	resources.Items = owned
+	return nil
+}
+
+// newReconciler builds a reconciler for local use. Events are printed to
+// stderr instead of being recorded on the Ingress.
+func newReconciler(c client.Client, scheme *runtime.Scheme, configFile string) (*controller.IngressReconciler, error) {
+	cfg := config.Default()
+	if configFile != "" {
+		var err error
+		if cfg, err = config.LoadFile(configFile); err != nil {
+			return nil, err
+		}
+	}
+	return controller.NewIngressReconciler(c, scheme, cfg, logr.Discard(), &stderrRecorder{}), nil
+}
+
+// stderrRecorder is an EventRecorder that prints warning events to stderr.
+type stderrRecorder struct{}
+
+func (stderrRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if eventtype == "Normal" {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s %s: %s\n", eventtype, reason, message)
+}
+
+func (r stderrRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r stderrRecorder) AnnotatedEventf(object runtime.Object, _ map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Eventf(object, eventtype, reason, messageFmt, args...)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+)
+
+// runExplain implements "pic explain".
+func runExplain(args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pic explain [flags] <namespace>/<ingress>")
+		fmt.Fprintln(fs.Output(), "\nExplains whether and how PIC exposes an Ingress from the live cluster.")
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+	configFile := fs.String("config", "", "PIC configuration file (tunnel mapping, default tunnel, backend scheme)")
+	kubeconfig := fs.String("kubeconfig", "", "path to the kubeconfig")
+	kubeContext := fs.String("context", "", "kubeconfig context")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	key, err := parseNamespacedName(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	scheme, err := newScheme()
+	if err != nil {
+		return err
+	}
+	c, err := newClient(scheme, *kubeconfig, *kubeContext)
+	if err != nil {
+		return err
+	}
+	r, err := newReconciler(c, scheme, *configFile)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	var ingress networkingv1.Ingress
+	if err := c.Get(ctx, key, &ingress); err != nil {
+		return fmt.Errorf("failed to get Ingress %s: %w", key, err)
+	}
+
+	exp, err := r.Explain(ctx, &ingress)
+	if err != nil {
+		return err
+	}
+
+	printExplanation(os.Stdout, key, exp)
+	return nil
+}
+
+// parseNamespacedName parses "<namespace>/<name>", defaulting to the "default" namespace.
+func parseNamespacedName(s string) (types.NamespacedName, error) {
+	parts := strings.Split(s, "/")
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+		return types.NamespacedName{Namespace: "default", Name: parts[0]}, nil
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+	}
+	return types.NamespacedName{}, fmt.Errorf("invalid Ingress reference %q, expected <namespace>/<name>", s)
+}
+
+func printExplanation(w io.Writer, key types.NamespacedName, exp *controller.Explanation) {
+	fmt.Fprintf(w, "Ingress:   %s\n", key)
+	fmt.Fprintf(w, "Action:    %s\n", exp.Action)
+	if exp.Managed {
+		fmt.Fprintf(w, "Managed:   yes (%s)\n", exp.ManagedReason)
+	} else {
+		fmt.Fprintf(w, "Managed:   no (%s)\n", exp.ManagedReason)
+	}
+	if exp.Shard != "" {
+		fmt.Fprintf(w, "Shard:     outside, left to another PIC instance (%s)\n", exp.Shard)
+	}
+	if exp.Paused {
+		fmt.Fprintf(w, "Paused:    yes, PangolinResources are left unchanged (%s)\n", controller.AnnotationPaused)
+	}
+	if exp.Disabled != "" {
+		fmt.Fprintf(w, "Disabled:  yes (%s)\n", exp.Disabled)
+	}
+	if exp.Schedule != "" {
+		fmt.Fprintf(w, "Schedule:  %s\n", exp.Schedule)
+	}
+	if exp.Expiry != "" {
+		fmt.Fprintf(w, "Expiry:    %s\n", exp.Expiry)
+	}
+
+	switch {
+	case exp.IngressClassError != nil:
+		fmt.Fprintf(w, "Class:     error: %v\n", exp.IngressClassError)
+	case exp.IngressClass != nil && exp.IngressClass.Config != nil:
+		fmt.Fprintf(w, "Class:     %s from %s (parameters %s)\n", exp.IngressClass.Name, exp.IngressClass.Source,
+			exp.IngressClass.ConfigRef())
+	case exp.IngressClass != nil && exp.IngressClass.Name != "":
+		fmt.Fprintf(w, "Class:     %s from %s\n", exp.IngressClass.Name, exp.IngressClass.Source)
+	}
+
+	if exp.Managed {
+		for _, d := range exp.Defaults {
+			fmt.Fprintf(w, "Default:   %s\n", d)
+		}
+
+		switch {
+		case exp.TunnelName == "":
+			fmt.Fprintf(w, "Tunnel:    unresolved: %v\n", exp.TunnelError)
+		case exp.TunnelError != nil:
+			fmt.Fprintf(w, "Tunnel:    %s (%s), not found: %v\n", exp.TunnelName, exp.TunnelSource, exp.TunnelError)
+		default:
+			fmt.Fprintf(w, "Tunnel:    %s/%s (%s)\n", exp.TunnelNamespace, exp.TunnelName, exp.TunnelSource)
+			if len(exp.TunnelAlternatives) > 0 {
+				fmt.Fprintf(w, "           ambiguous, also in %s\n", strings.Join(exp.TunnelAlternatives, ", "))
+			}
+		}
+
+		fmt.Fprintln(w, "Hosts:")
+		if len(exp.Hosts) == 0 {
+			fmt.Fprintln(w, "  (none)")
+		}
+		for _, host := range exp.Hosts {
+			fmt.Fprintf(w, "  %s\n", host.Host)
+			fmt.Fprintf(w, "    subdomain: %q  domain: %q\n", host.Subdomain, host.Domain)
+			if host.Err != nil {
+				fmt.Fprintf(w, "    error:     %v\n", host.Err)
+				continue
+			}
+			if host.Adopt {
+				fmt.Fprintf(w, "    resource:  %s (will be adopted)\n", host.ResourceName)
+			} else {
+				fmt.Fprintf(w, "    resource:  %s\n", host.ResourceName)
+			}
+			for _, old := range host.Replaces {
+				if host.RenameErr != nil {
+					fmt.Fprintf(w, "    replaces:  %s (kept, rename stalled: %v)\n", old, host.RenameErr)
+					continue
+				}
+				fmt.Fprintf(w, "    replaces:  %s (deleted once %s is Ready)\n", old, host.ResourceName)
+			}
+			if host.Resource == nil {
+				fmt.Fprintln(w, "    status:    not created yet")
+			} else {
+				status := host.Resource.Status
+				phase := status.Phase
+				if phase == "" {
+					phase = "Unknown"
+				}
+				fmt.Fprintf(w, "    status:    %s", phase)
+				if status.URL != "" {
+					fmt.Fprintf(w, " %s", status.URL)
+				}
+				fmt.Fprintln(w)
+				for _, cond := range status.Conditions {
+					fmt.Fprintf(w, "      %s=%s %s: %s\n", cond.Type, cond.Status, cond.Reason, cond.Message)
+				}
+			}
+			for _, conflict := range host.Conflicts {
+				fmt.Fprintf(w, "    conflict:  %s\n", conflict)
+			}
+		}
+	}
+
+	if len(exp.Orphans) > 0 {
+		fmt.Fprintln(w, "Orphans (will be deleted):")
+		for _, name := range exp.Orphans {
+			fmt.Fprintf(w, "  %s/%s\n", key.Namespace, name)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Command pic is the command-line companion of the Pangolin Ingress Controller.
+// It runs the controller's rendering logic locally to preview and debug what
+// PIC does with a given Ingress.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: pic <command> [flags]
+
+Commands:
+  render    Render the PangolinResources PIC would create for Ingress manifests
+  explain   Explain why an Ingress in the cluster is or is not exposed
+
+Run "pic <command> -h" for command flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "render":
+		err = runRender(os.Args[2:])
+	case "explain":
+		err = runExplain(os.Args[2:])
+	case "-h", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/manifest"
+)
+
+// runRender implements "pic render".
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pic render [flags] [FILE...]")
+		fmt.Fprintln(fs.Output(), "\nRenders the PangolinResources PIC would create for the Ingresses in FILE (or stdin).")
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+	configFile := fs.String("config", "", "PIC configuration file (tunnel mapping, default tunnel, backend scheme)")
+	namespace := fs.String("namespace", "default", "namespace for Ingresses that do not set one")
+	tunnelNamespace := fs.String("tunnel-namespace", "", "namespace of the PangolinTunnel when not looked up in a cluster")
+	diff := fs.Bool("diff", false, "compare against the live cluster instead of printing manifests")
+	kubeconfig := fs.String("kubeconfig", "", "path to the kubeconfig used with --diff")
+	kubeContext := fs.String("context", "", "kubeconfig context used with --diff")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	scheme, err := newScheme()
+	if err != nil {
+		return err
+	}
+
+	ingresses, err := manifest.ReadIngresses(fs.Args(), *namespace)
+	if err != nil {
+		return err
+	}
+
+	var c client.Client
+	if *diff {
+		if c, err = newClient(scheme, *kubeconfig, *kubeContext); err != nil {
+			return err
+		}
+	}
+
+	r, err := newReconciler(c, scheme, *configFile)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	first := true
+	for i := range ingresses {
+		ingress := &ingresses[i]
+		key := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+
+		if *diff {
+			if err := renderDiff(ctx, c, r, ingress); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Without a cluster only the class name conventions apply
+		if !r.IsManaged(ingress, nil) {
+			fmt.Fprintf(os.Stderr, "Skipping %s: not managed by PIC\n", key)
+			continue
+		}
+
+		tunnelRef, err := r.ResolveTunnel(ingress, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", key, err)
+			continue
+		}
+		tunnelNs, tunnelName, err := controller.SplitTunnelRef(tunnelRef)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", key, err)
+			continue
+		}
+		if tunnelNs == "" {
+			tunnelNs = *tunnelNamespace
+		}
+		for _, host := range r.Render(ingress, nil, tunnelName, tunnelNs) {
+			if host.Err != nil {
+				fmt.Fprintf(os.Stderr, "%s: host %q: %v\n", key, host.Host, host.Err)
+				continue
+			}
+			if err := manifest.WriteResource(os.Stdout, host.Resource, first); err != nil {
+				return err
+			}
+			first = false
+		}
+	}
+
+	return nil
+}
+
+// renderDiff prints the changes PIC would make to the live cluster for the
+// Ingress: the plan Reconcile would carry out, as pic explain reports it.
+func renderDiff(
+	ctx context.Context,
+	c client.Client,
+	r *controller.IngressReconciler,
+	ingress *networkingv1.Ingress,
+) error {
+	key := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+	fmt.Printf("Ingress %s\n", key)
+
+	// Plan with the live UID so labels and owner references compare equal
+	var live networkingv1.Ingress
+	if err := c.Get(ctx, key, &live); err == nil {
+		ingress.UID = live.UID
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get Ingress %s: %w", key, err)
+	}
+
+	exp, err := r.Explain(ctx, ingress)
+	if err != nil {
+		return err
+	}
+	if len(exp.Hosts) == 0 {
+		fmt.Printf("  ! %s\n", exp.Action)
+	}
+
+	for _, host := range exp.Hosts {
+		if host.Err != nil {
+			fmt.Printf("  ! host %q: %v\n", host.Host, host.Err)
+			continue
+		}
+		if host.Resource == nil {
+			fmt.Printf("  + %s (%s) would be created\n", host.ResourceName, host.Host)
+			continue
+		}
+
+		diffs, err := controller.DiffPangolinResource(host.Resource, host.Desired)
+		if err != nil {
+			return err
+		}
+		if host.Adopt {
+			fmt.Printf("  ~ %s (%s) would be adopted\n", host.ResourceName, host.Host)
+		} else if len(diffs) == 0 {
+			fmt.Printf("  = %s (%s) unchanged\n", host.ResourceName, host.Host)
+			continue
+		} else {
+			fmt.Printf("  ~ %s (%s) would be updated\n", host.ResourceName, host.Host)
+		}
+		for _, d := range diffs {
+			fmt.Printf("      %s\n", d)
+		}
+	}
+
+	for _, name := range exp.Orphans {
+		fmt.Printf("  - %s would be deleted\n", name)
+	}
+
+	return nil
+}
@@ -1,11 +1,55 @@
 // Package config provides configuration loading for the Pangolin Ingress Controller.
+//
+// Configuration comes from an optional YAML/JSON file (PIC_CONFIG_FILE,
+// typically a mounted ConfigMap) with environment variables taking precedence
+// over the file. The file can be reloaded at runtime with a Watcher.
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// APIVersion is the current version of the configuration file schema.
+	APIVersion = "pic.ingress.k8s.io/v1alpha1"
+
+	// Kind is the kind of the configuration file.
+	Kind = "ControllerConfig"
+
+	// EnvConfigFile names the environment variable holding the config file path.
+	EnvConfigFile = "PIC_CONFIG_FILE"
+
+	// PreferIngressNamespace in TunnelPreference stands for the namespace of
+	// the Ingress referencing the tunnel.
+	PreferIngressNamespace = "$ingress"
+
+	// PreferControllerNamespace in TunnelPreference stands for the
+	// namespace PIC runs in.
+	PreferControllerNamespace = "$controller"
+
+	// OrphanPolicyDelete deletes PangolinResources whose Ingress is gone.
+	OrphanPolicyDelete = "delete"
+
+	// OrphanPolicyAdopt hands PangolinResources whose Ingress is gone to a
+	// recreated Ingress of the same name, and deletes the rest.
+	OrphanPolicyAdopt = "adopt"
+
+	// ExpiryActionDisable keeps the PangolinResources of an expired Ingress
+	// with Enabled set to false.
+	ExpiryActionDisable = "disable"
+
+	// ExpiryActionDelete deletes the PangolinResources of an expired Ingress.
+	ExpiryActionDelete = "delete"
 )
 
 // Config holds the runtime configuration for PIC.
@@ -28,50 +72,215 @@ type Config struct {
 
 	// WatchNamespaces limits which namespaces to watch (empty = all)
 	WatchNamespaces []string
+
+	// RequireTunnelGrants requires a PangolinTunnelGrant for every
+	// cross-namespace tunnel reference (default true). Turning it off lets
+	// namespaces without grants be referenced from any namespace.
+	RequireTunnelGrants bool
+
+	// TunnelPreference orders the namespaces searched for a tunnel referenced
+	// by bare name: namespace names, PreferIngressNamespace or
+	// PreferControllerNamespace. Unlisted namespaces come last, in name order.
+	TunnelPreference []string
+
+	// ControllerNamespace is the namespace PIC runs in (empty if unknown)
+	ControllerNamespace string
+
+	// OrphanSweepInterval is how often to look for PangolinResources whose
+	// Ingress is gone (0 = never)
+	OrphanSweepInterval time.Duration
+
+	// OrphanPolicy is what the sweep does with them ("delete" or "adopt")
+	OrphanPolicy string
+
+	// IngressFinalizer holds deleted Ingresses until their PangolinResources
+	// are gone
+	IngressFinalizer bool
+
+	// FinalizerTimeout is how long the finalizer waits before giving up
+	// (0 = wait forever)
+	FinalizerTimeout time.Duration
+
+	// ExpiryAction is what happens to the PangolinResources of an expired
+	// Ingress ("disable" or "delete")
+	ExpiryAction string
+
+	// ShardSelector is a label selector limiting the Ingresses this instance
+	// manages (empty = all)
+	ShardSelector string
+
+	// ShardTunnels limits the Ingresses this instance manages to those
+	// using one of these tunnels, as "name" or "namespace/name" (empty = all)
+	ShardTunnels []string
+
+	// InstanceID identifies this PIC installation. It labels the
+	// PangolinResources it creates, and resources labeled by another
+	// instance are left alone (empty = unlabeled)
+	InstanceID string
+
+	// ManagementKubeconfig is the kubeconfig of the management cluster
+	// running pangolin-operator. When set, PangolinResources are written to
+	// and tunnels read from that cluster (empty = the local cluster)
+	ManagementKubeconfig string
+
+	// ClusterName identifies the local cluster in PangolinResource names,
+	// labels and display names in the management cluster; required with
+	// ManagementKubeconfig
+	ClusterName string
 }
 
-// Load reads configuration from environment variables.
-func Load() (*Config, error) {
-	cfg := &Config{
-		DefaultTunnelName: getEnv("PIC_DEFAULT_TUNNEL_NAME", "default"),
-		BackendScheme:     getEnv("PIC_BACKEND_SCHEME", "http"),
-		LogLevel:          getEnv("PIC_LOG_LEVEL", "info"),
+// File is the on-disk representation of the configuration.
+//
+// Example:
+//
+//	apiVersion: pic.ingress.k8s.io/v1alpha1
+//	kind: ControllerConfig
+//	defaultTunnelName: default
+//	backendScheme: http
+//	resyncPeriod: 5m
+//	logLevel: info
+//	watchNamespaces: [apps, staging]
+//	requireTunnelGrants: true
+//	tunnelPreference: [$ingress, $controller, pangolin-system]
+//	orphanSweepInterval: 10m
+//	orphanPolicy: delete
+//	ingressFinalizer: true
+//	finalizerTimeout: 10m
+//	shardSelector: team=payments
+//	shardTunnels: [site-a, edge/site-b]
+//	instanceID: prod
+//	managementKubeconfig: /etc/pic-management/kubeconfig
+//	clusterName: edge-1
+//	tunnelMapping:
+//	  eu: tunnel-eu
+//	  us: tunnel-us
+type File struct {
+	// APIVersion is the schema version; empty means the current version.
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind must be ControllerConfig when set.
+	Kind string `json:"kind,omitempty"`
+
+	// DefaultTunnelName is the tunnel used when ingressClassName is exactly "pangolin".
+	DefaultTunnelName string `json:"defaultTunnelName,omitempty"`
+
+	// BackendScheme is the protocol for backend services ("http" or "https").
+	BackendScheme string `json:"backendScheme,omitempty"`
+
+	// ResyncPeriod is how often to re-reconcile all resources, as a Go duration.
+	ResyncPeriod string `json:"resyncPeriod,omitempty"`
+
+	// LogLevel controls logging verbosity ("debug", "info", "warn", "error").
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// WatchNamespaces limits which namespaces to watch (empty = all).
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
+	// TunnelMapping maps ingressClass suffixes to tunnel names.
+	TunnelMapping map[string]string `json:"tunnelMapping,omitempty"`
+
+	// RequireTunnelGrants requires a PangolinTunnelGrant for every
+	// cross-namespace tunnel reference.
+	RequireTunnelGrants *bool `json:"requireTunnelGrants,omitempty"`
+
+	// TunnelPreference orders the namespaces searched for a tunnel
+	// referenced by bare name.
+	TunnelPreference []string `json:"tunnelPreference,omitempty"`
+
+	// OrphanSweepInterval is how often to look for PangolinResources whose
+	// Ingress is gone, as a Go duration; "0" disables the sweep.
+	OrphanSweepInterval string `json:"orphanSweepInterval,omitempty"`
+
+	// OrphanPolicy is what the sweep does with them ("delete" or "adopt").
+	OrphanPolicy string `json:"orphanPolicy,omitempty"`
+
+	// IngressFinalizer holds deleted Ingresses until their PangolinResources
+	// are gone.
+	IngressFinalizer *bool `json:"ingressFinalizer,omitempty"`
+
+	// FinalizerTimeout is how long the finalizer waits, as a Go duration;
+	// "0" waits forever.
+	FinalizerTimeout string `json:"finalizerTimeout,omitempty"`
+
+	// ExpiryAction is what happens to the PangolinResources of an expired
+	// Ingress ("disable" or "delete").
+	ExpiryAction string `json:"expiryAction,omitempty"`
+
+	// ShardSelector is a label selector limiting the Ingresses this
+	// instance manages.
+	ShardSelector string `json:"shardSelector,omitempty"`
+
+	// ShardTunnels limits the Ingresses this instance manages to those
+	// using one of these tunnels.
+	ShardTunnels []string `json:"shardTunnels,omitempty"`
+
+	// InstanceID identifies this PIC installation on the PangolinResources
+	// it creates.
+	InstanceID string `json:"instanceID,omitempty"`
+
+	// ManagementKubeconfig is the path of the management cluster's
+	// kubeconfig.
+	ManagementKubeconfig string `json:"managementKubeconfig,omitempty"`
+
+	// ClusterName identifies the local cluster in the management cluster.
+	ClusterName string `json:"clusterName,omitempty"`
+}
+
+// Default returns the configuration used when nothing is set.
+func Default() *Config {
+	return &Config{
+		DefaultTunnelName: "default",
+		BackendScheme:     "http",
+		ResyncPeriod:      5 * time.Minute,
+		LogLevel:          "info",
 		TunnelMapping:     make(map[string]string),
-	}
+		TunnelPreference:  []string{PreferIngressNamespace, PreferControllerNamespace},
 
-	// Parse resync period
-	resyncStr := getEnv("PIC_RESYNC_PERIOD", "5m")
-	resync, err := time.ParseDuration(resyncStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid PIC_RESYNC_PERIOD %q: %w", resyncStr, err)
+		RequireTunnelGrants: true,
+		OrphanSweepInterval: 10 * time.Minute,
+		OrphanPolicy:        OrphanPolicyDelete,
+		FinalizerTimeout:    10 * time.Minute,
+		ExpiryAction:        ExpiryActionDisable,
 	}
-	cfg.ResyncPeriod = resync
+}
 
-	// Parse watch namespaces
-	if ns := getEnv("PIC_WATCH_NAMESPACES", ""); ns != "" {
-		cfg.WatchNamespaces = strings.Split(ns, ",")
-		for i := range cfg.WatchNamespaces {
-			cfg.WatchNamespaces[i] = strings.TrimSpace(cfg.WatchNamespaces[i])
+// Load reads configuration from the file named by PIC_CONFIG_FILE, if set,
+// and then from environment variables, which override file settings.
+func Load() (*Config, error) {
+	return loadFrom(getEnv(EnvConfigFile, ""))
+}
+
+// loadFrom reads configuration from the file at path (if not empty) and
+// then from environment variables.
+func loadFrom(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := applyFile(cfg, path); err != nil {
+			return nil, err
 		}
 	}
 
-	// Parse tunnel mapping
-	if mapping := getEnv("PIC_TUNNEL_CLASS_MAPPING", ""); mapping != "" {
-		lines := strings.Split(mapping, "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				value := strings.TrimSpace(parts[1])
-				cfg.TunnelMapping[key] = value
-			}
-		}
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadFile reads configuration from a YAML or JSON file only, ignoring
+// environment variables. Settings missing from the file keep their defaults.
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+	if err := applyFile(cfg, path); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -84,6 +293,348 @@ func MustLoad() *Config {
 	return cfg
 }
 
+// Validate checks every setting and reports all invalid ones at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if msgs := validateTunnelRef(c.DefaultTunnelName); len(msgs) > 0 {
+		errs = append(errs, fmt.Errorf("defaultTunnelName %q: %s", c.DefaultTunnelName, strings.Join(msgs, ", ")))
+	}
+
+	switch c.BackendScheme {
+	case "http", "https":
+	default:
+		errs = append(errs, fmt.Errorf("backendScheme %q: must be \"http\" or \"https\"", c.BackendScheme))
+	}
+
+	if c.ResyncPeriod <= 0 {
+		errs = append(errs, fmt.Errorf("resyncPeriod %s: must be positive", c.ResyncPeriod))
+	}
+
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("logLevel %q: must be one of debug, info, warn, error", c.LogLevel))
+	}
+
+	if c.OrphanSweepInterval < 0 {
+		errs = append(errs, fmt.Errorf("orphanSweepInterval %s: must not be negative", c.OrphanSweepInterval))
+	}
+
+	if c.FinalizerTimeout < 0 {
+		errs = append(errs, fmt.Errorf("finalizerTimeout %s: must not be negative", c.FinalizerTimeout))
+	}
+
+	switch c.OrphanPolicy {
+	case OrphanPolicyDelete, OrphanPolicyAdopt:
+	default:
+		errs = append(errs, fmt.Errorf("orphanPolicy %q: must be %q or %q", c.OrphanPolicy, OrphanPolicyDelete, OrphanPolicyAdopt))
+	}
+
+	switch c.ExpiryAction {
+	case ExpiryActionDisable, ExpiryActionDelete:
+	default:
+		errs = append(errs, fmt.Errorf("expiryAction %q: must be %q or %q", c.ExpiryAction, ExpiryActionDisable, ExpiryActionDelete))
+	}
+
+	if _, err := labels.Parse(c.ShardSelector); err != nil {
+		errs = append(errs, fmt.Errorf("shardSelector %q: %w", c.ShardSelector, err))
+	}
+
+	for _, tunnel := range c.ShardTunnels {
+		if msgs := validateTunnelRef(tunnel); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("shardTunnels %q: %s", tunnel, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if msgs := validation.IsValidLabelValue(c.InstanceID); len(msgs) > 0 {
+		errs = append(errs, fmt.Errorf("instanceID %q: %s", c.InstanceID, strings.Join(msgs, ", ")))
+	}
+
+	switch {
+	case c.ManagementKubeconfig != "" && c.ClusterName == "":
+		errs = append(errs, errors.New("clusterName: required with managementKubeconfig"))
+	case c.ManagementKubeconfig == "" && c.ClusterName != "":
+		errs = append(errs, fmt.Errorf("clusterName %q: only used with managementKubeconfig", c.ClusterName))
+	case c.ClusterName != "":
+		if msgs := validation.IsDNS1123Label(c.ClusterName); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("clusterName %q: %s", c.ClusterName, strings.Join(msgs, ", ")))
+		}
+	}
+
+	for _, ns := range c.WatchNamespaces {
+		if msgs := validation.IsDNS1123Label(ns); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("watchNamespaces %q: %s", ns, strings.Join(msgs, ", ")))
+		}
+	}
+
+	for _, entry := range c.TunnelPreference {
+		if entry == PreferIngressNamespace || entry == PreferControllerNamespace {
+			continue
+		}
+		if msgs := validation.IsDNS1123Label(entry); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("tunnelPreference %q: must be %s, %s or a namespace: %s",
+				entry, PreferIngressNamespace, PreferControllerNamespace, strings.Join(msgs, ", ")))
+		}
+	}
+
+	for suffix, tunnel := range c.TunnelMapping {
+		if msgs := validation.IsDNS1123Label(suffix); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("tunnelMapping key %q: %s", suffix, strings.Join(msgs, ", ")))
+		}
+		if msgs := validateTunnelRef(tunnel); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("tunnelMapping[%s] %q: %s", suffix, tunnel, strings.Join(msgs, ", ")))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validateTunnelRef validates a tunnel reference, either "name" or "namespace/name".
+func validateTunnelRef(ref string) []string {
+	namespace, name, qualified := strings.Cut(ref, "/")
+	if !qualified {
+		return validation.IsDNS1123Subdomain(ref)
+	}
+	msgs := validation.IsDNS1123Label(namespace)
+	return append(msgs, validation.IsDNS1123Subdomain(name)...)
+}
+
+// TunnelSettingsEqual reports whether two configurations resolve tunnels and
+// manage Ingresses and PangolinResources identically, i.e. whether switching
+// from one to the other requires re-reconciling Ingresses.
+func TunnelSettingsEqual(a, b *Config) bool {
+	return a.DefaultTunnelName == b.DefaultTunnelName &&
+		a.BackendScheme == b.BackendScheme &&
+		a.RequireTunnelGrants == b.RequireTunnelGrants &&
+		a.IngressFinalizer == b.IngressFinalizer &&
+		a.ExpiryAction == b.ExpiryAction &&
+		a.ShardSelector == b.ShardSelector &&
+		reflect.DeepEqual(a.ShardTunnels, b.ShardTunnels) &&
+		a.InstanceID == b.InstanceID &&
+		reflect.DeepEqual(a.TunnelPreference, b.TunnelPreference) &&
+		reflect.DeepEqual(a.TunnelMapping, b.TunnelMapping)
+}
+
+// applyFile overlays the settings of a configuration file onto cfg.
+// Unknown fields and unsupported versions are rejected.
+func applyFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file File
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	if file.APIVersion != "" && file.APIVersion != APIVersion {
+		return fmt.Errorf("config file %s: unsupported apiVersion %q, expected %q", path, file.APIVersion, APIVersion)
+	}
+	if file.Kind != "" && file.Kind != Kind {
+		return fmt.Errorf("config file %s: unsupported kind %q, expected %q", path, file.Kind, Kind)
+	}
+
+	if file.DefaultTunnelName != "" {
+		cfg.DefaultTunnelName = file.DefaultTunnelName
+	}
+	if file.BackendScheme != "" {
+		cfg.BackendScheme = file.BackendScheme
+	}
+	if file.ResyncPeriod != "" {
+		resync, err := time.ParseDuration(file.ResyncPeriod)
+		if err != nil {
+			return fmt.Errorf("config file %s: invalid resyncPeriod %q: %w", path, file.ResyncPeriod, err)
+		}
+		cfg.ResyncPeriod = resync
+	}
+	if file.LogLevel != "" {
+		cfg.LogLevel = file.LogLevel
+	}
+	if file.OrphanSweepInterval != "" {
+		interval, err := time.ParseDuration(file.OrphanSweepInterval)
+		if err != nil {
+			return fmt.Errorf("config file %s: invalid orphanSweepInterval %q: %w", path, file.OrphanSweepInterval, err)
+		}
+		cfg.OrphanSweepInterval = interval
+	}
+	if file.OrphanPolicy != "" {
+		cfg.OrphanPolicy = file.OrphanPolicy
+	}
+	if file.IngressFinalizer != nil {
+		cfg.IngressFinalizer = *file.IngressFinalizer
+	}
+	if file.FinalizerTimeout != "" {
+		timeout, err := time.ParseDuration(file.FinalizerTimeout)
+		if err != nil {
+			return fmt.Errorf("config file %s: invalid finalizerTimeout %q: %w", path, file.FinalizerTimeout, err)
+		}
+		cfg.FinalizerTimeout = timeout
+	}
+	if file.ExpiryAction != "" {
+		cfg.ExpiryAction = file.ExpiryAction
+	}
+	if file.ShardSelector != "" {
+		cfg.ShardSelector = file.ShardSelector
+	}
+	if len(file.ShardTunnels) > 0 {
+		cfg.ShardTunnels = file.ShardTunnels
+	}
+	if file.InstanceID != "" {
+		cfg.InstanceID = file.InstanceID
+	}
+	if file.ManagementKubeconfig != "" {
+		cfg.ManagementKubeconfig = file.ManagementKubeconfig
+	}
+	if file.ClusterName != "" {
+		cfg.ClusterName = file.ClusterName
+	}
+	if len(file.WatchNamespaces) > 0 {
+		cfg.WatchNamespaces = file.WatchNamespaces
+	}
+	if file.RequireTunnelGrants != nil {
+		cfg.RequireTunnelGrants = *file.RequireTunnelGrants
+	}
+	if len(file.TunnelPreference) > 0 {
+		cfg.TunnelPreference = file.TunnelPreference
+	}
+	if len(file.TunnelMapping) > 0 {
+		cfg.TunnelMapping = make(map[string]string, len(file.TunnelMapping))
+		for key, value := range file.TunnelMapping {
+			cfg.TunnelMapping[key] = value
+		}
+	}
+
+	return nil
+}
+
+// applyEnv overlays the settings of environment variables onto cfg.
+func applyEnv(cfg *Config) error {
+	cfg.DefaultTunnelName = getEnv("PIC_DEFAULT_TUNNEL_NAME", cfg.DefaultTunnelName)
+	cfg.BackendScheme = getEnv("PIC_BACKEND_SCHEME", cfg.BackendScheme)
+	cfg.LogLevel = getEnv("PIC_LOG_LEVEL", cfg.LogLevel)
+	cfg.ControllerNamespace = getEnv("PIC_CONTROLLER_NAMESPACE", cfg.ControllerNamespace)
+
+	// Parse resync period
+	if resyncStr := getEnv("PIC_RESYNC_PERIOD", ""); resyncStr != "" {
+		resync, err := time.ParseDuration(resyncStr)
+		if err != nil {
+			return fmt.Errorf("invalid PIC_RESYNC_PERIOD %q: %w", resyncStr, err)
+		}
+		cfg.ResyncPeriod = resync
+	}
+
+	// Parse orphan sweep settings
+	if intervalStr := getEnv("PIC_ORPHAN_SWEEP_INTERVAL", ""); intervalStr != "" {
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			return fmt.Errorf("invalid PIC_ORPHAN_SWEEP_INTERVAL %q: %w", intervalStr, err)
+		}
+		cfg.OrphanSweepInterval = interval
+	}
+	cfg.OrphanPolicy = getEnv("PIC_ORPHAN_POLICY", cfg.OrphanPolicy)
+
+	// Parse finalizer settings
+	if enabled := getEnv("PIC_INGRESS_FINALIZER", ""); enabled != "" {
+		value, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return fmt.Errorf("invalid PIC_INGRESS_FINALIZER %q: %w", enabled, err)
+		}
+		cfg.IngressFinalizer = value
+	}
+	if timeoutStr := getEnv("PIC_FINALIZER_TIMEOUT", ""); timeoutStr != "" {
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return fmt.Errorf("invalid PIC_FINALIZER_TIMEOUT %q: %w", timeoutStr, err)
+		}
+		cfg.FinalizerTimeout = timeout
+	}
+
+	cfg.ExpiryAction = getEnv("PIC_EXPIRY_ACTION", cfg.ExpiryAction)
+
+	// Parse shard settings
+	cfg.ShardSelector = getEnv("PIC_SHARD_SELECTOR", cfg.ShardSelector)
+	if tunnels := getEnv("PIC_SHARD_TUNNELS", ""); tunnels != "" {
+		cfg.ShardTunnels = splitList(tunnels)
+	}
+	cfg.InstanceID = getEnv("PIC_INSTANCE_ID", cfg.InstanceID)
+
+	// Multi-cluster settings
+	cfg.ManagementKubeconfig = getEnv("PIC_MANAGEMENT_KUBECONFIG", cfg.ManagementKubeconfig)
+	cfg.ClusterName = getEnv("PIC_CLUSTER_NAME", cfg.ClusterName)
+
+	// Parse tunnel grant requirement
+	if require := getEnv("PIC_REQUIRE_TUNNEL_GRANTS", ""); require != "" {
+		value, err := strconv.ParseBool(require)
+		if err != nil {
+			return fmt.Errorf("invalid PIC_REQUIRE_TUNNEL_GRANTS %q: %w", require, err)
+		}
+		cfg.RequireTunnelGrants = value
+	}
+
+	// Parse watch namespaces
+	if ns := getEnv("PIC_WATCH_NAMESPACES", ""); ns != "" {
+		cfg.WatchNamespaces = splitList(ns)
+	}
+
+	// Parse tunnel preference
+	if preference := getEnv("PIC_TUNNEL_PREFERENCE", ""); preference != "" {
+		cfg.TunnelPreference = splitList(preference)
+	}
+
+	// Parse tunnel mapping
+	if mapping := getEnv("PIC_TUNNEL_CLASS_MAPPING", ""); mapping != "" {
+		parsed, err := parseTunnelMapping(mapping)
+		if err != nil {
+			return fmt.Errorf("invalid PIC_TUNNEL_CLASS_MAPPING: %w", err)
+		}
+		cfg.TunnelMapping = parsed
+	}
+
+	return nil
+}
+
+// parseTunnelMapping parses "suffix=tunnel" lines. Blank lines and lines
+// starting with "#" are ignored; anything else that is not a key=value pair
+// with non-empty sides, or a repeated key, is an error.
+func parseTunnelMapping(mapping string) (map[string]string, error) {
+	result := make(map[string]string)
+	for i, line := range strings.Split(mapping, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d %q: expected <suffix>=<tunnel>", i+1, line)
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		if key == "" || value == "" {
+			return nil, fmt.Errorf("line %d %q: suffix and tunnel must not be empty", i+1, line)
+		}
+		if _, dup := result[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate suffix %q", i+1, key)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// splitList splits a comma-separated list, trimming spaces around items.
+func splitList(list string) []string {
+	items := strings.Split(list, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
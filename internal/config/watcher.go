@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+)
+
+// Watcher reloads the configuration when the config file changes on disk.
+//
+// The file's directory is watched rather than the file itself so that
+// ConfigMap volume updates, which atomically swap a symlink, are detected.
+// Each change reloads the file and applies environment overrides again, as
+// Load does. An invalid file is logged and ignored; the last
+// valid configuration stays active.
+//
+// Watcher implements manager.Runnable and can be added to a controller-runtime manager.
+type Watcher struct {
+	path string
+	log  logr.Logger
+
+	mu        sync.RWMutex
+	current   *Config
+	listeners []func(previous, next *Config)
+}
+
+// NewWatcher returns a Watcher for the file at path, starting from initial.
+func NewWatcher(path string, initial *Config, log logr.Logger) *Watcher {
+	return &Watcher{
+		path:    path,
+		log:     log.WithValues("file", path),
+		current: initial,
+	}
+}
+
+// Current returns the active configuration.
+func (w *Watcher) Current() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// OnChange registers fn to be called after each successful reload that
+// changed the configuration. Register listeners before calling Start.
+func (w *Watcher) OnChange(fn func(previous, next *Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listeners = append(w.listeners, fn)
+}
+
+// Start watches the config file until ctx is cancelled.
+func (w *Watcher) Start(ctx context.Context) error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer fsWatcher.Close()
+
+	if err := fsWatcher.Add(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", filepath.Dir(w.path), err)
+	}
+
+	w.log.Info("Watching configuration file for changes")
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+				w.Reload()
+			}
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.log.Error(err, "Configuration file watch error")
+		}
+	}
+}
+
+// Reload loads the configuration again and notifies listeners if it changed.
+func (w *Watcher) Reload() {
+	next, err := loadFrom(w.path)
+	if err != nil {
+		w.log.Error(err, "Ignoring invalid configuration, keeping previous settings")
+		return
+	}
+
+	w.mu.Lock()
+	previous := w.current
+	if reflect.DeepEqual(previous, next) {
+		w.mu.Unlock()
+		return
+	}
+	w.current = next
+	listeners := append([]func(previous, next *Config){}, w.listeners...)
+	w.mu.Unlock()
+
+	if previous.ResyncPeriod != next.ResyncPeriod ||
+		previous.LogLevel != next.LogLevel ||
+		!reflect.DeepEqual(previous.WatchNamespaces, next.WatchNamespaces) ||
+		previous.ManagementKubeconfig != next.ManagementKubeconfig ||
+		previous.ClusterName != next.ClusterName {
+		w.log.Info("resyncPeriod, logLevel, watchNamespaces, managementKubeconfig and clusterName changes take effect after a restart")
+	}
+
+	w.log.Info("Configuration reloaded")
+	for _, fn := range listeners {
+		fn(previous, next)
+	}
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"sort"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// AnnotationAdopt set to "true" on a PangolinResource that PIC does not own
+// lets an Ingress serving the same host take it over instead of creating a
+// second resource for the domain.
+const AnnotationAdopt = "pangolin.ingress.k8s.io/adopt"
+
+// matchExisting finds the live PangolinResource a desired resource should be
+// applied to, among resources. The resource with the generated name comes
+// first; otherwise one the Ingress adopted earlier for the same host;
+// otherwise an uncontrolled one opted in with AnnotationAdopt, in which case
+// adopt is true. Other resources the Ingress controls for the host are
+// replaced under the generated name, see predecessors. It returns nil if the desired
+// resource is new. Callers apply desired under the returned resource's name.
+func matchExisting(
+	ingress *networkingv1.Ingress,
+	desired *pangolincrd.PangolinResource,
+	resources []pangolincrd.PangolinResource,
+) (match *pangolincrd.PangolinResource, adopt bool) {
+	var owned, adoptable []*pangolincrd.PangolinResource
+	for i := range resources {
+		resource := &resources[i]
+		if resource.Namespace != desired.Namespace {
+			continue
+		}
+		if resource.Name == desired.Name {
+			return resource, false
+		}
+		if !servesSameHost(resource, desired) {
+			continue
+		}
+		if resource.Annotations[AnnotationAdopt] != "true" {
+			continue
+		}
+		switch uid, controlled := controllerUID(resource); {
+		case controlled && uid == ingress.UID:
+			owned = append(owned, resource)
+		case !controlled:
+			adoptable = append(adoptable, resource)
+		}
+	}
+
+	if match := firstByName(owned); match != nil {
+		return match, false
+	}
+	if match := firstByName(adoptable); match != nil {
+		return match, true
+	}
+	return nil, false
+}
+
+// servesSameHost reports whether two PangolinResources expose the same
+// subdomain and domain.
+func servesSameHost(a, b *pangolincrd.PangolinResource) bool {
+	if a.Spec.HTTPConfig == nil || b.Spec.HTTPConfig == nil {
+		return false
+	}
+	return strings.EqualFold(a.Spec.HTTPConfig.DomainName, b.Spec.HTTPConfig.DomainName) &&
+		strings.EqualFold(a.Spec.HTTPConfig.Subdomain, b.Spec.HTTPConfig.Subdomain)
+}
+
+// firstByName returns the resource with the lowest name, so the choice among
+// several candidates is stable.
+func firstByName(resources []*pangolincrd.PangolinResource) *pangolincrd.PangolinResource {
+	if len(resources) == 0 {
+		return nil
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+	return resources[0]
+}
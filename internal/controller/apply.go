@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// FieldManager is the server-side apply field manager used for every
+// PangolinResource written by PIC.
+const FieldManager = "pangolin-ingress-controller"
+
+// fieldSet is a decoded managedFields FieldsV1 set. Keys are "f:<name>" for
+// fields, "." for the field holding the set, or "k:", "v:" and "i:" for list
+// entries; an empty set owns the field it is stored under.
+type fieldSet map[string]interface{}
+
+// fieldOwners returns the field sets of the resource's managers: PIC's own,
+// and those of every other manager by name. Status updates made by
+// pangolin-operator through the status subresource are skipped.
+func fieldOwners(resource *pangolincrd.PangolinResource) (fieldSet, map[string]fieldSet) {
+	var own fieldSet
+	foreign := make(map[string]fieldSet)
+	for _, entry := range resource.ManagedFields {
+		if entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		var fields fieldSet
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		if entry.Manager == FieldManager {
+			own = mergeFieldSets(own, fields)
+			continue
+		}
+		foreign[entry.Manager] = mergeFieldSets(foreign[entry.Manager], fields)
+	}
+	return own, foreign
+}
+
+// mergeFieldSets returns the union of two field sets.
+func mergeFieldSets(a, b fieldSet) fieldSet {
+	if a == nil {
+		return b
+	}
+	for key, value := range b {
+		bChild, _ := value.(map[string]interface{})
+		aChild, _ := a[key].(map[string]interface{})
+		a[key] = map[string]interface{}(mergeFieldSets(aChild, bChild))
+	}
+	return a
+}
+
+// owns reports whether the set owns the field at path, a field below it, or
+// the field holding it. An index into a list with keyed or set entries
+// counts as owned when any entry is, the list being matched as a whole.
+func (s fieldSet) owns(path []string) bool {
+	if s == nil {
+		return false
+	}
+	node := s
+	for _, segment := range path {
+		if _, ok := node["."]; ok {
+			return true
+		}
+		key := "f:" + segment
+		if index, isIndex := strings.CutPrefix(segment, "["); isIndex {
+			key = "i:" + strings.TrimSuffix(index, "]")
+			if _, ok := node[key]; !ok {
+				for entry := range node {
+					if strings.HasPrefix(entry, "k:") || strings.HasPrefix(entry, "v:") {
+						return true
+					}
+				}
+				return false
+			}
+		}
+		child, ok := node[key].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if len(child) == 0 {
+			return true
+		}
+		node = child
+	}
+	return true
+}
+
+// managersOwning returns the sorted names of the managers whose sets own path.
+func managersOwning(managers map[string]fieldSet, path []string) []string {
+	var names []string
+	for name, fields := range managers {
+		if fields.owns(path) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/util"
+)
+
+// collisionFallback returns the name desired moves to when live, the
+// resource under its name, was created for another host: their generated
+// names collide. It returns "" if they do not. Resources that do not record
+// their host, created before PIC did, are never taken for a collision.
+func collisionFallback(
+	ingress *networkingv1.Ingress,
+	desired *pangolincrd.PangolinResource,
+	live *pangolincrd.PangolinResource,
+) string {
+	liveHost := live.Annotations[AnnotationHost]
+	host := desired.Annotations[AnnotationHost]
+	if liveHost == "" || strings.EqualFold(liveHost, host) {
+		return ""
+	}
+	return util.GenerateLongClusterName(desired.Labels[LabelCluster], ingress.Namespace, ingress.Name, host)
+}
@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
+)
+
+// defaultableAnnotations are the Ingress annotations a namespace can default.
+var defaultableAnnotations = []string{
+	AnnotationTunnelName,
+	AnnotationSSO,
+	AnnotationBlockAccess,
+}
+
+// NamespaceDefaults are Ingress annotation defaults set for a namespace,
+// through Namespace annotations or PangolinIngressPolicy objects.
+type NamespaceDefaults struct {
+	// Annotations maps annotation keys to default values.
+	Annotations map[string]string
+
+	// Sources maps annotation keys to where the default came from.
+	Sources map[string]string
+}
+
+// set records a default, replacing any earlier one for the key.
+func (d *NamespaceDefaults) set(key, value, source string) {
+	d.Annotations[key] = value
+	d.Sources[key] = source
+}
+
+// Apply returns the Ingress with the defaults merged beneath its own
+// annotations. The Ingress is copied only if a default applies. The default
+// tunnel is not merged: it ranks below the IngressClass, see tunnel.
+func (d *NamespaceDefaults) Apply(ingress *networkingv1.Ingress) *networkingv1.Ingress {
+	missing := d.missing(ingress)
+	if len(missing) == 0 {
+		return ingress
+	}
+
+	merged := ingress.DeepCopy()
+	if merged.Annotations == nil {
+		merged.Annotations = make(map[string]string, len(missing))
+	}
+	for _, key := range missing {
+		merged.Annotations[key] = d.Annotations[key]
+	}
+	return merged
+}
+
+// missing returns the sorted keys of the defaults the Ingress does not set itself.
+func (d *NamespaceDefaults) missing(ingress *networkingv1.Ingress) []string {
+	if d == nil {
+		return nil
+	}
+	var keys []string
+	for key := range d.Annotations {
+		if key == AnnotationTunnelName {
+			continue
+		}
+		if _, ok := ingress.Annotations[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tunnel returns the namespace's default tunnel and a description of where
+// it came from, or empty strings if none is set. It replaces the controller's
+// default tunnel, but not a tunnel chosen by the Ingress or its IngressClass.
+func (d *NamespaceDefaults) tunnel() (string, string) {
+	if d == nil || d.Annotations[AnnotationTunnelName] == "" {
+		return "", ""
+	}
+	return d.Annotations[AnnotationTunnelName], "namespace default from " + d.Sources[AnnotationTunnelName]
+}
+
+// resolveNamespaceDefaults reads the defaults of a namespace. PangolinIngressPolicy
+// objects are applied in name order, then Namespace annotations override them.
+func (r *IngressReconciler) resolveNamespaceDefaults(ctx context.Context, namespace string) (*NamespaceDefaults, error) {
+	defaults := &NamespaceDefaults{
+		Annotations: make(map[string]string),
+		Sources:     make(map[string]string),
+	}
+
+	var policies piccrd.PangolinIngressPolicyList
+	if err := r.List(ctx, &policies, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list PangolinIngressPolicies in %s: %w", namespace, err)
+	}
+	sort.Slice(policies.Items, func(i, j int) bool {
+		return policies.Items[i].Name < policies.Items[j].Name
+	})
+	for _, policy := range policies.Items {
+		source := fmt.Sprintf("PangolinIngressPolicy %s/%s", policy.Namespace, policy.Name)
+		if policy.Spec.TunnelName != "" {
+			defaults.set(AnnotationTunnelName, policy.Spec.TunnelName, source)
+		}
+		if policy.Spec.SSO != nil {
+			defaults.set(AnnotationSSO, strconv.FormatBool(*policy.Spec.SSO), source)
+		}
+		if policy.Spec.BlockAccess != nil {
+			defaults.set(AnnotationBlockAccess, strconv.FormatBool(*policy.Spec.BlockAccess), source)
+		}
+	}
+
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return defaults, nil
+		}
+		return nil, fmt.Errorf("failed to get Namespace %s: %w", namespace, err)
+	}
+	for _, key := range defaultableAnnotations {
+		if value, ok := ns.Annotations[key]; ok {
+			defaults.set(key, value, "Namespace "+namespace+" annotation")
+		}
+	}
+
+	return defaults, nil
+}
+
+// ingressesInNamespace enqueues every Ingress in the namespace of obj, or
+// in obj itself when it is a Namespace.
+func (r *IngressReconciler) ingressesInNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	namespace := obj.GetNamespace()
+	if _, ok := obj.(*corev1.Namespace); ok {
+		namespace = obj.GetName()
+	}
+
+	var ingresses networkingv1.IngressList
+	if err := r.List(ctx, &ingresses, client.InNamespace(namespace)); err != nil {
+		r.Log.Error(err, "Failed to list Ingresses", "namespace", namespace)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(ingresses.Items))
+	for _, ingress := range ingresses.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name},
+		})
+	}
+	return requests
+}
+
+// namespaceChanged passes Namespace updates that change a defaultable
+// annotation or the labels cluster policies select on. Creations and
+// deletions are ignored: a new namespace has no Ingresses yet and a deleted
+// one takes its Ingresses with it.
+var namespaceChanged = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if !reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) {
+			return true
+		}
+		oldAnnotations := e.ObjectOld.GetAnnotations()
+		newAnnotations := e.ObjectNew.GetAnnotations()
+		for _, key := range defaultableAnnotations {
+			if oldAnnotations[key] != newAnnotations[key] {
+				return true
+			}
+		}
+		return false
+	},
+}
@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/util"
+)
+
+// maxDriftEventEntries caps the number of field diffs included in a DriftDetected event.
+const maxDriftEventEntries = 10
+
+// AnnotationRenderedHash records a hash of the state PIC rendered from the
+// Ingress when it last applied a PangolinResource. While the Ingress renders
+// the same state, every field of it was applied, so a field missing from the
+// live resource was removed by hand even though no manager owns it any more.
+const AnnotationRenderedHash = "pic.ingress.k8s.io/rendered-hash"
+
+// renderedHash returns the hash of the spec, labels and annotations rendered
+// for the resource, recorded in AnnotationRenderedHash.
+func renderedHash(desired *pangolincrd.PangolinResource) (string, error) {
+	raw, err := json.Marshal(struct {
+		Spec        pangolincrd.PangolinResourceSpec `json:"spec"`
+		Labels      map[string]string                `json:"labels,omitempty"`
+		Annotations map[string]string                `json:"annotations,omitempty"`
+	}{desired.Spec, desired.Labels, desired.Annotations})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// detectDrift returns the field-level differences between the live
+// PangolinResource and the desired state, covering the whole spec and the
+// labels and annotations PIC sets. Metadata PIC does not set is ignored.
+func detectDrift(existing, desired *pangolincrd.PangolinResource) ([]string, error) {
+	diffs, err := util.Diff("spec", existing.Spec, desired.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	labelDiffs, err := util.Diff("metadata.labels",
+		ownedSubset(existing.Labels, desired.Labels), desired.Labels)
+	if err != nil {
+		return nil, err
+	}
+	diffs = append(diffs, labelDiffs...)
+
+	annotationDiffs, err := util.Diff("metadata.annotations",
+		ownedSubset(existing.Annotations, desired.Annotations), desired.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	return append(diffs, annotationDiffs...), nil
+}
+
+// ownedSubset returns the entries of current whose keys are present in desired.
+func ownedSubset(current, desired map[string]string) map[string]string {
+	subset := make(map[string]string)
+	for key := range desired {
+		if value, ok := current[key]; ok {
+			subset[key] = value
+		}
+	}
+	return subset
+}
+
+// fieldDrift is a diff on a field another field manager owns, or on a field
+// PIC applied that was removed.
+type fieldDrift struct {
+	diff     string
+	path     []string
+	managers []string
+	// shared is set when PIC's apply owns the field too, as it does after
+	// applying a manually changed value it was told to keep.
+	shared bool
+}
+
+// foreignDrift returns the diffs on fields owned by a manager other than
+// PIC. Diffs on fields only PIC owns are changes coming from the Ingress,
+// whatever else other managers have written to the resource. When the
+// Ingress still renders what PIC last applied, rendered is set and fields
+// missing from the live resource are drift too: removing a field leaves no
+// manager owning it.
+func foreignDrift(existing *pangolincrd.PangolinResource, diffs []string, rendered bool) []fieldDrift {
+	own, foreign := fieldOwners(existing)
+	var drift []fieldDrift
+	for _, diff := range diffs {
+		path := diffPath(diff)
+		managers := managersOwning(foreign, path)
+		if len(managers) == 0 && !(rendered && removed(diff) && !own.owns(path)) {
+			continue
+		}
+		drift = append(drift, fieldDrift{diff: diff, path: path, managers: managers, shared: own.owns(path)})
+	}
+	return drift
+}
+
+// removed reports whether the diff is on a field missing from the live
+// resource.
+func removed(diff string) bool {
+	_, change, _ := strings.Cut(diff, ": ")
+	return strings.HasPrefix(change, util.Unset+" -> ")
+}
+
+// driftDiffs returns the diffs of the drifted fields; fields PIC's apply
+// shares with another manager are included only when withShared is set.
+func driftDiffs(drift []fieldDrift, withShared bool) []string {
+	var diffs []string
+	for _, field := range drift {
+		if withShared || !field.shared {
+			diffs = append(diffs, field.diff)
+		}
+	}
+	return diffs
+}
+
+// driftManagers returns the sorted names of the managers owning the fields
+// that drifted, leaving out fields PIC shares with them.
+func driftManagers(drift []fieldDrift) []string {
+	seen := make(map[string]bool)
+	var managers []string
+	for _, field := range drift {
+		if field.shared {
+			continue
+		}
+		for _, manager := range field.managers {
+			if !seen[manager] {
+				seen[manager] = true
+				managers = append(managers, manager)
+			}
+		}
+	}
+	sort.Strings(managers)
+	return managers
+}
+
+// diffPath splits the field path of a util.Diff entry into segments:
+// "spec.targets[0].port" becomes spec, targets, [0], port and
+// "metadata.labels[app.kubernetes.io/name]" becomes metadata, labels,
+// app.kubernetes.io/name. List indexes keep their brackets.
+func diffPath(diff string) []string {
+	path, _, _ := strings.Cut(diff, ": ")
+	var segments []string
+	for path != "" {
+		switch {
+		case path[0] == '.':
+			path = path[1:]
+		case path[0] == '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return append(segments, path)
+			}
+			key := path[1:end]
+			if strings.Trim(key, "0123456789") == "" {
+				key = path[:end+1]
+			}
+			segments = append(segments, key)
+			path = path[end+1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segments = append(segments, path[:end])
+			path = path[end:]
+		}
+	}
+	return segments
+}
+
+// keepManualChanges copies the live value of each drifted field into
+// desired, so applying it keeps the manual change while every other field
+// follows the Ingress. Lists are kept as a whole.
+func keepManualChanges(desired, existing *pangolincrd.PangolinResource, drift []fieldDrift) error {
+	live, err := runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+	if err != nil {
+		return err
+	}
+	wanted, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return err
+	}
+	for _, field := range drift {
+		path := field.path
+		for i, segment := range path {
+			if strings.HasPrefix(segment, "[") {
+				path = path[:i]
+				break
+			}
+		}
+		value, found, err := unstructured.NestedFieldCopy(live, path...)
+		if err != nil {
+			return err
+		}
+		if !found {
+			unstructured.RemoveNestedField(wanted, path...)
+			continue
+		}
+		if err := unstructured.SetNestedField(wanted, value, path...); err != nil {
+			return err
+		}
+	}
+	kept := &pangolincrd.PangolinResource{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(wanted, kept); err != nil {
+		return err
+	}
+	kept.DeepCopyInto(desired)
+	return nil
+}
+
+// allowsManualChanges reports whether manual edits to the PangolinResource
+// should be kept, either because the resource itself or its Ingress carries
+// the allow-manual-changes annotation.
+func allowsManualChanges(ingress *networkingv1.Ingress, resource *pangolincrd.PangolinResource) bool {
+	return strings.ToLower(resource.Annotations[AnnotationAllowManualChanges]) == "true" ||
+		strings.ToLower(ingress.Annotations[AnnotationAllowManualChanges]) == "true"
+}
+
+// formatDrift renders field diffs for an event message.
+func formatDrift(diffs []string) string {
+	if len(diffs) <= maxDriftEventEntries {
+		return strings.Join(diffs, "; ")
+	}
+	return fmt.Sprintf("%s; and %d more", strings.Join(diffs[:maxDriftEventEntries], "; "),
+		len(diffs)-maxDriftEventEntries)
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+)
+
+const (
+	// AnnotationExpiresAt is the RFC 3339 time after which an Ingress is no
+	// longer exposed.
+	AnnotationExpiresAt = "pangolin.ingress.k8s.io/expires-at"
+
+	// AnnotationExpiresAfter is how long after its creation an Ingress is
+	// exposed, as a Go duration.
+	AnnotationExpiresAfter = "pangolin.ingress.k8s.io/expires-after"
+)
+
+// errInvalidExpiry is returned by expiryOf for a malformed expiry annotation.
+var errInvalidExpiry = errors.New("invalid expiry")
+
+// expiryOf returns when the Ingress expires, or the zero time if it does
+// not. With both annotations set the earlier time applies.
+func expiryOf(ingress *networkingv1.Ingress) (time.Time, error) {
+	var expiry time.Time
+	if value, ok := ingress.Annotations[AnnotationExpiresAt]; ok {
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %s %q is not an RFC 3339 time", errInvalidExpiry, AnnotationExpiresAt, value)
+		}
+		expiry = at
+	}
+	if value, ok := ingress.Annotations[AnnotationExpiresAfter]; ok {
+		after, err := time.ParseDuration(value)
+		if err != nil || after < 0 {
+			return time.Time{}, fmt.Errorf("%w: %s %q is not a positive duration", errInvalidExpiry, AnnotationExpiresAfter, value)
+		}
+		at := ingress.CreationTimestamp.Add(after)
+		if expiry.IsZero() || at.Before(expiry) {
+			expiry = at
+		}
+	}
+	return expiry, nil
+}
+
+// exposure is the expiry and schedule state of an Ingress at a point in time.
+type exposure struct {
+	expiry    time.Time
+	expiryErr error
+
+	// expired is also set for an invalid expiry, which hides the Ingress.
+	expired bool
+
+	// window is closed once the Ingress expired.
+	window exposureWindow
+}
+
+// evaluateExposure evaluates the Ingress's expiry and schedule at now.
+func evaluateExposure(ingress *networkingv1.Ingress, now time.Time) exposure {
+	state := exposure{window: evaluateSchedule(ingress, now)}
+	state.expiry, state.expiryErr = expiryOf(ingress)
+	state.expired = state.expiryErr != nil || (!state.expiry.IsZero() && !now.Before(state.expiry))
+	if state.expired {
+		state.window = exposureWindow{}
+	}
+	return state
+}
+
+// describeExpiry summarizes the expiry of an Ingress for pic explain.
+func describeExpiry(expiry time.Time, err error, now time.Time, action string) string {
+	switch {
+	case err != nil:
+		return fmt.Sprintf("hidden: %v", err)
+	case expiry.IsZero():
+		return ""
+	case now.Before(expiry):
+		return fmt.Sprintf("expires at %s", expiry.Format(time.RFC3339))
+	case action == config.ExpiryActionDelete:
+		return fmt.Sprintf("expired at %s, PangolinResources are deleted", expiry.Format(time.RFC3339))
+	default:
+		return fmt.Sprintf("expired at %s, PangolinResources are disabled", expiry.Format(time.RFC3339))
+	}
+}
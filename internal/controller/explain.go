@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/util"
+)
+
+// Explanation describes how PIC handles an Ingress.
+type Explanation struct {
+	// Action is what Reconcile does with the Ingress.
+	Action string
+
+	// Managed reports whether PIC manages the Ingress.
+	Managed bool
+
+	// ManagedReason explains the Managed decision.
+	ManagedReason string
+
+	// Shard explains why the Ingress belongs to another PIC instance's
+	// shard, empty if it is in this instance's.
+	Shard string
+
+	// Paused reports that AnnotationPaused stops PIC from changing the
+	// Ingress's PangolinResources.
+	Paused bool
+
+	// Disabled explains why the PangolinResources are turned off in
+	// Pangolin, empty if they are not.
+	Disabled string
+
+	// Schedule describes the state of the Ingress's exposure schedule and
+	// its next transition, empty without a schedule.
+	Schedule string
+
+	// Expiry describes when the Ingress expires or what happened when it
+	// did, empty without an expiry annotation.
+	Expiry string
+
+	// IngressClass is the IngressClass and its parameters, nil if they could
+	// not be read.
+	IngressClass *IngressClassSettings
+
+	// IngressClassError explains why the IngressClass could not be resolved.
+	IngressClassError error
+
+	// Defaults lists the namespace defaults applied to the Ingress, as
+	// "annotation=value (source)".
+	Defaults []string
+
+	// TunnelName is the resolved tunnel name.
+	TunnelName string
+
+	// TunnelSource describes where the tunnel name came from.
+	TunnelSource string
+
+	// TunnelNamespace is the namespace of the PangolinTunnel, if found.
+	TunnelNamespace string
+
+	// TunnelError explains why the tunnel could not be resolved or found.
+	TunnelError error
+
+	// TunnelAlternatives lists other namespaces holding a tunnel of the same
+	// name when the choice fell back to name order.
+	TunnelAlternatives []string
+
+	// Hosts describes each unique host of the Ingress.
+	Hosts []HostExplanation
+
+	// Orphans lists the PangolinResources the Ingress controls that no host
+	// needs; Reconcile deletes them.
+	Orphans []string
+}
+
+// HostExplanation describes how a single Ingress host is exposed.
+type HostExplanation struct {
+	// Host is the Ingress host.
+	Host string
+
+	// Subdomain and Domain are the values sent to Pangolin, after overrides.
+	Subdomain string
+	Domain    string
+
+	// ResourceName is the PangolinResource name: the generated one, or that
+	// of an existing resource serving the host.
+	ResourceName string
+
+	// Adopt reports that Resource is not owned by PIC yet and will be taken
+	// over because it carries the adopt annotation.
+	Adopt bool
+
+	// Replaces lists resources the Ingress controls for the host under an
+	// old name. They are deleted once Resource is Ready.
+	Replaces []string
+
+	// RenameErr explains why the resources in Replaces are kept: the new
+	// resource failed or did not become Ready in time.
+	RenameErr error
+
+	// Err explains why the host cannot be exposed.
+	Err error
+
+	// Desired is the PangolinResource Reconcile applies for the host, nil
+	// if none could be built.
+	Desired *pangolincrd.PangolinResource
+
+	// Resource is the live PangolinResource, nil if it does not exist yet.
+	Resource *pangolincrd.PangolinResource
+
+	// Conflicts lists other PangolinResources serving the same subdomain and domain,
+	// or a resource with the generated name owned by something else.
+	Conflicts []string
+}
+
+// Explain reports how PIC handles the Ingress: what Reconcile does with it
+// and why, the tunnel it resolves to, the resources planned for each host
+// and their live status, and any conflicting or orphaned resources. It
+// prints the plan Reconcile carries out.
+func (r *IngressReconciler) Explain(ctx context.Context, ingress *networkingv1.Ingress) (*Explanation, error) {
+	plan, err := r.plan(ctx, ingress)
+	if err != nil {
+		return nil, err
+	}
+
+	exp := &Explanation{
+		Action:             plan.describe(),
+		Managed:            plan.managed,
+		ManagedReason:      plan.managedReason,
+		Shard:              plan.shard,
+		Paused:             plan.action == actionPause,
+		IngressClass:       plan.class,
+		IngressClassError:  plan.classErr,
+		Defaults:           plan.defaults,
+		TunnelName:         plan.tunnelRef,
+		TunnelSource:       plan.tunnelSource,
+		TunnelError:        plan.tunnelErr,
+		TunnelAlternatives: plan.target.ambiguous,
+		Orphans:            resourceNames(plan.orphans),
+	}
+	sort.Strings(exp.Orphans)
+	if plan.target.name != "" {
+		exp.TunnelName, exp.TunnelNamespace = plan.target.name, plan.target.namespace
+	}
+	if plan.managed {
+		exp.Disabled = disabledReason(plan.ingress, plan.target)
+	}
+	if plan.window.scheduled {
+		exp.Schedule = plan.window.describe()
+	}
+	exp.Expiry = describeExpiry(plan.expiry, plan.expiryErr, r.now(), r.config().ExpiryAction)
+
+	// Every PangolinResource, to report other resources serving a host
+	var all pangolincrd.PangolinResourceList
+	if len(plan.hosts) > 0 {
+		if err := r.resources().List(ctx, &all); err != nil {
+			return nil, fmt.Errorf("failed to list PangolinResources: %w", err)
+		}
+	}
+
+	for _, planned := range plan.hosts {
+		host := HostExplanation{Host: planned.Host, Err: planned.Err, Adopt: planned.adopt}
+		desired := planned.Resource
+		if desired == nil {
+			if subdomain, domain, err := util.SplitHost(planned.Host); err == nil {
+				host.Subdomain, host.Domain = subdomain, domain
+			}
+			exp.Hosts = append(exp.Hosts, host)
+			continue
+		}
+		host.ResourceName = desired.Name
+		host.Subdomain = desired.Spec.HTTPConfig.Subdomain
+		host.Domain = desired.Spec.HTTPConfig.DomainName
+		if collision := planned.collision; collision != nil {
+			host.Conflicts = append(host.Conflicts,
+				fmt.Sprintf("%s/%s was created for host %q, using %s", collision.Namespace, collision.Name,
+					collision.Annotations[AnnotationHost], desired.Name))
+		}
+		if planned.Err != nil {
+			exp.Hosts = append(exp.Hosts, host)
+			continue
+		}
+		host.Desired = desired
+		host.Replaces = resourceNames(planned.predecessors)
+		host.RenameErr = planned.renameErr
+
+		if live := planned.live; live != nil {
+			host.Resource = live
+			if id, foreign := r.foreignInstance(live); foreign {
+				host.Conflicts = append(host.Conflicts,
+					fmt.Sprintf("%s/%s was created by PIC instance %q and is left unchanged", live.Namespace, live.Name, id))
+			} else if uid, controlled := controllerUID(live); !host.Adopt && (!controlled || uid != ingress.UID) {
+				host.Conflicts = append(host.Conflicts,
+					fmt.Sprintf("%s/%s exists but is not controlled by this Ingress", live.Namespace, live.Name))
+			}
+		}
+
+		for _, other := range all.Items {
+			if other.Namespace == desired.Namespace && (other.Name == desired.Name || slices.Contains(host.Replaces, other.Name)) {
+				continue
+			}
+			if other.Spec.HTTPConfig != nil &&
+				other.Spec.HTTPConfig.DomainName == host.Domain &&
+				other.Spec.HTTPConfig.Subdomain == host.Subdomain {
+				host.Conflicts = append(host.Conflicts,
+					fmt.Sprintf("%s/%s also serves %s", other.Namespace, other.Name, planned.Host))
+			}
+		}
+
+		exp.Hosts = append(exp.Hosts, host)
+	}
+
+	return exp, nil
+}
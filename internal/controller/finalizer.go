@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/metrics"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+const (
+	// FinalizerCleanup holds a deleted Ingress until its PangolinResources,
+	// and so the Pangolin resources behind them, are gone.
+	FinalizerCleanup = "pic.ingress.k8s.io/cleanup"
+
+	// AnnotationForceRelease set to "true" on a deleted Ingress removes the
+	// cleanup finalizer without waiting.
+	AnnotationForceRelease = "pangolin.ingress.k8s.io/force-release"
+
+	// finalizerPollInterval is how often a deleted Ingress is rechecked
+	// while its PangolinResources are being deleted.
+	finalizerPollInterval = 10 * time.Second
+)
+
+// syncFinalizer adds the cleanup finalizer to a managed Ingress when it is
+// enabled, and removes it when it is not. Multi-cluster mode always needs it:
+// garbage collection cannot follow the Ingress into the management cluster.
+func (r *IngressReconciler) syncFinalizer(ctx context.Context, ingress *networkingv1.Ingress) error {
+	if r.config().IngressFinalizer || r.Management != nil {
+		return r.patchFinalizer(ctx, ingress, controllerutil.AddFinalizer)
+	}
+	return r.patchFinalizer(ctx, ingress, controllerutil.RemoveFinalizer)
+}
+
+// patchFinalizer adds or removes the cleanup finalizer, writing only if that
+// changed the Ingress.
+func (r *IngressReconciler) patchFinalizer(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	change func(client.Object, string) bool,
+) error {
+	base := ingress.DeepCopy()
+	if !change(ingress, FinalizerCleanup) {
+		return nil
+	}
+	if err := r.Patch(ctx, ingress, client.MergeFrom(base)); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to update finalizers of Ingress %s/%s: %w", ingress.Namespace, ingress.Name, err)
+	}
+	return nil
+}
+
+// finalize handles a deleted Ingress holding the cleanup finalizer. It
+// deletes the Ingress's PangolinResources, or hands them to another Ingress
+// serving their host, and releases the Ingress once they are gone, after
+// FinalizerTimeout, or when AnnotationForceRelease is set.
+func (r *IngressReconciler) finalize(ctx context.Context, ingress *networkingv1.Ingress) (ctrl.Result, error) {
+	log := r.Log.WithValues("ingress", types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace})
+	metrics.SetIngressUnmanaged(types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}.String())
+
+	if !controllerutil.ContainsFinalizer(ingress, FinalizerCleanup) {
+		// PangolinResources are garbage collected via ownerReference, or
+		// by the orphan sweep in multi-cluster mode
+		return ctrl.Result{}, nil
+	}
+
+	if ingress.Annotations[AnnotationForceRelease] == "true" {
+		log.Info("Releasing Ingress without waiting for cleanup")
+		r.Recorder.Event(ingress, corev1.EventTypeWarning, "CleanupForced",
+			fmt.Sprintf("Annotation %s is set, released without waiting for PangolinResources", AnnotationForceRelease))
+		return ctrl.Result{}, r.patchFinalizer(ctx, ingress, controllerutil.RemoveFinalizer)
+	}
+
+	var resourceList pangolincrd.PangolinResourceList
+	if err := r.resources().List(ctx, &resourceList,
+		client.InNamespace(ingress.Namespace),
+		client.MatchingFields{OwnerUIDIndex: string(ingress.UID)},
+	); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list PangolinResources: %w", err)
+	}
+
+	var pending []string
+	for _, resource := range r.ownResources(resourceList.Items) {
+		// A renamed Ingress serving the same host takes the resource over
+		// and replaces it once its own is Ready
+		if resource.DeletionTimestamp.IsZero() {
+			successor, err := r.successorFor(ctx, ingress, &resource)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if successor != nil {
+				if err := r.adoptResource(ctx, &resource, successor); err != nil {
+					return ctrl.Result{}, err
+				}
+				r.Recorder.Event(ingress, corev1.EventTypeNormal, "HandedOver",
+					fmt.Sprintf("Handed PangolinResource %s over to Ingress %s", resource.Name, successor.Name))
+				continue
+			}
+		}
+
+		pending = append(pending, resource.Name)
+		if !resource.DeletionTimestamp.IsZero() {
+			continue
+		}
+		log.Info("Deleting PangolinResource of deleted Ingress", "resource", resource.Name)
+		if err := r.resources().Delete(ctx, &resource); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to delete PangolinResource %s: %w", resource.Name, err)
+		}
+		r.Recorder.Event(ingress, corev1.EventTypeNormal, "Deleted",
+			fmt.Sprintf("Deleted PangolinResource %s (Ingress deleted)", resource.Name))
+		metrics.ResourceOperationsTotal.WithLabelValues(metrics.OperationDelete).Inc()
+		metrics.StopReadyTimer(resource.Namespace + "/" + resource.Name)
+	}
+
+	if len(pending) == 0 {
+		log.Info("PangolinResources deleted, releasing Ingress")
+		return ctrl.Result{}, r.patchFinalizer(ctx, ingress, controllerutil.RemoveFinalizer)
+	}
+
+	waited := r.now().Sub(ingress.DeletionTimestamp.Time)
+	if timeout := r.config().FinalizerTimeout; timeout > 0 && waited >= timeout {
+		log.Info("Timed out waiting for PangolinResources, releasing Ingress", "pending", pending)
+		r.Recorder.Event(ingress, corev1.EventTypeWarning, "CleanupTimedOut",
+			fmt.Sprintf("PangolinResources %s still exist after %s, released anyway",
+				strings.Join(pending, ", "), timeout))
+		return ctrl.Result{}, r.patchFinalizer(ctx, ingress, controllerutil.RemoveFinalizer)
+	}
+
+	r.recordState(ingress, plannedEvent{
+		eventType: corev1.EventTypeNormal,
+		reason:    "WaitingForCleanup",
+		message:   fmt.Sprintf("Waiting for pangolin-operator to delete PangolinResources %s", strings.Join(pending, ", ")),
+		state:     strings.Join(pending, ", "),
+	})
+	// Deleting a PangolinResource requeues the Ingress; poll in case it does not
+	return ctrl.Result{RequeueAfter: finalizerPollInterval}, nil
+}
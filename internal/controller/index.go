@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// OwnerUIDIndex is the cache index of PangolinResources by the UID of the
+// Ingress owning them, from the LabelIngressUID label and the controller
+// owner reference.
+const OwnerUIDIndex = "pic.ingress.k8s.io/owner-uid"
+
+// TunnelNameIndex is the cache index of PangolinTunnels by name. It is named
+// after the metadata.name field selector so the same lookup also works
+// against the API server, as the pic CLI does.
+const TunnelNameIndex = "metadata.name"
+
+// IndexTunnelName extracts the TunnelNameIndex value of a PangolinTunnel.
+func IndexTunnelName(obj client.Object) []string {
+	return []string{obj.GetName()}
+}
+
+// IndexOwnerUID extracts the OwnerUIDIndex values of a PangolinResource.
+func IndexOwnerUID(obj client.Object) []string {
+	var uids []string
+	if uid := obj.GetLabels()[LabelIngressUID]; uid != "" {
+		uids = append(uids, uid)
+	}
+	if ref := metav1.GetControllerOf(obj); ref != nil && ref.Kind == "Ingress" {
+		if uid := string(ref.UID); len(uids) == 0 || uids[0] != uid {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+// SetupIndexes registers the cache indexes the reconciler's lookups rely on.
+// It must run before the manager starts.
+func SetupIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &pangolincrd.PangolinTunnel{}, TunnelNameIndex, IndexTunnelName); err != nil {
+		return fmt.Errorf("failed to index PangolinTunnels by name: %w", err)
+	}
+	if err := indexer.IndexField(ctx, &pangolincrd.PangolinResource{}, OwnerUIDIndex, IndexOwnerUID); err != nil {
+		return fmt.Errorf("failed to index PangolinResources by owner: %w", err)
+	}
+	return nil
+}
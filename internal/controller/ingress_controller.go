@@ -7,8 +7,12 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -17,10 +21,18 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/metrics"
 	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
 	"github.com/wizzz/pangolin-ingress-controller/internal/util"
 )
 
@@ -43,6 +55,10 @@ const (
 	// AnnotationBlockAccess blocks access until authenticated.
 	AnnotationBlockAccess = "pangolin.ingress.k8s.io/block-access"
 
+	// AnnotationAllowManualChanges stops PIC from reverting manual edits to a
+	// PangolinResource. Set on the PangolinResource or its Ingress.
+	AnnotationAllowManualChanges = "pangolin.ingress.k8s.io/allow-manual-changes"
+
 	// LabelIngressUID identifies the source Ingress.
 	LabelIngressUID = "pic.ingress.k8s.io/uid"
 
@@ -59,6 +75,9 @@ const (
 	IngressClassPrefix = "pangolin-"
 )
 
+// errNoBackends is returned when none of a host's paths has a Service backend.
+var errNoBackends = errors.New("no valid backends found in Ingress paths")
+
 // HostPathGroup groups all HTTP paths for a single host.
 // Used internally during multi-host reconciliation to aggregate paths
 // from potentially multiple rules that share the same host.
@@ -74,144 +93,310 @@ type IngressReconciler struct {
 	Config   *config.Config
 	Log      logr.Logger
 	Recorder record.EventRecorder
+
+	// configMu guards Config, which UpdateConfig replaces on hot reload.
+	configMu sync.RWMutex
+
+	// statesMu guards states, the state last reported by each state event,
+	// keyed by Ingress and reason.
+	statesMu sync.Mutex
+	states   map[string]string
+
+	// Management is the cluster PangolinResources are written to in
+	// multi-cluster mode; nil writes them next to the Ingresses.
+	Management *ManagementCluster
+
+	// Clock returns the current time for schedules; time.Now if nil.
+	Clock func() time.Time
+
+	// requeueAll triggers a reconcile of every Ingress.
+	requeueAll chan event.GenericEvent
 }
 
-// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingressclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=pic.ingress.k8s.io,resources=pangoliningressclassconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=pic.ingress.k8s.io,resources=pangolinnamespacedingressclassconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=pic.ingress.k8s.io,resources=pangoliningresspolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=pic.ingress.k8s.io,resources=pangolinclusterpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=pic.ingress.k8s.io,resources=pangolintunnelgrants,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
 // +kubebuilder:rbac:groups=tunnel.pangolin.io,resources=pangolintunnels,verbs=get;list;watch
 // +kubebuilder:rbac:groups=tunnel.pangolin.io,resources=pangolinresources,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
-// Reconcile handles Ingress changes.
+// Reconcile handles Ingress changes by carrying out the plan decided for the
+// Ingress.
 func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("ingress", req.NamespacedName)
 	log.V(1).Info("Reconciling Ingress")
 
+	ctx, span := tracer.Start(ctx, "Reconcile", trace.WithAttributes(
+		attrIngressNamespace.String(req.Namespace),
+		attrIngressName.String(req.Name),
+	))
+	defer span.End()
+
 	// Fetch the Ingress
 	var ingress networkingv1.Ingress
 	if err := r.Get(ctx, req.NamespacedName, &ingress); err != nil {
 		if apierrors.IsNotFound(err) {
-			// Ingress deleted - PangolinResource will be garbage collected via ownerReference
+			// Ingress deleted - PangolinResource will be garbage collected via ownerReference,
+			// or by the orphan sweep in multi-cluster mode
 			log.V(1).Info("Ingress not found, assuming deleted")
+			metrics.SetIngressUnmanaged(req.NamespacedName.String())
+			metrics.SetIngressExpiry(req.NamespacedName.String(), time.Time{})
+			r.forgetStates(req.NamespacedName.String())
 			return ctrl.Result{}, nil
 		}
 		log.Error(err, "Failed to get Ingress")
+		recordError(span, err)
 		return ctrl.Result{}, err
 	}
 
-	// Check if this Ingress is managed by PIC
-	if !r.isManaged(&ingress) {
-		log.V(1).Info("Ingress not managed by PIC")
-		// If we previously managed it, delete the PangolinResource
-		return r.handleUnmanaged(ctx, &ingress)
-	}
-
-	// Resolve tunnel name
-	tunnelName, err := r.resolveTunnel(&ingress)
+	// Decide what to do, then do it
+	plan, err := r.plan(ctx, &ingress)
 	if err != nil {
-		log.Error(err, "Failed to resolve tunnel")
-		r.Recorder.Event(&ingress, corev1.EventTypeWarning, "TunnelResolutionFailed", err.Error())
+		log.Error(err, "Failed to plan Ingress")
+		recordError(span, err)
 		return ctrl.Result{}, err
 	}
+	key := req.NamespacedName.String()
+	r.recordEvents(&ingress, key, plan.events)
+	if plan.expired {
+		metrics.SetIngressExpiry(key, time.Time{})
+	} else {
+		metrics.SetIngressExpiry(key, plan.expiry)
+	}
 
-	// Validate tunnel exists and get its namespace
-	tunnelNamespace, err := r.validateTunnel(ctx, tunnelName)
-	if err != nil {
-		log.Error(err, "Tunnel validation failed", "tunnel", tunnelName)
-		r.Recorder.Event(&ingress, corev1.EventTypeWarning, "TunnelNotFound",
-			fmt.Sprintf("Tunnel %q not found", tunnelName))
+	// Resources breaking a cluster policy go even when the rest is left alone
+	if len(plan.violations) > 0 {
+		if err := r.deleteResources(ctx, &ingress, plan.violations, "policy violation"); err != nil {
+			log.Error(err, "Failed to delete PangolinResources breaking a cluster policy")
+			recordError(span, err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	switch plan.action {
+	case actionIgnore:
+		log.V(1).Info("Ingress outside shard, leaving it to another instance", "reason", plan.reason)
+		return r.withdraw(ctx, &ingress, plan.orphans, "outside shard")
+
+	case actionFinalize:
+		result, err := r.finalize(ctx, &ingress)
+		if err != nil {
+			log.Error(err, "Failed to finalize Ingress")
+			recordError(span, err)
+		}
+		return result, err
+
+	case actionPause:
+		log.V(1).Info("Ingress is paused, leaving PangolinResources unchanged")
+		return ctrl.Result{}, nil
+
+	case actionFail:
+		log.Error(plan.err, "Failed to resolve Ingress")
+		recordError(span, plan.err)
+		return ctrl.Result{}, plan.err
+
+	case actionUnmanage:
+		log.V(1).Info("Ingress not managed by PIC")
+		metrics.SetIngressUnmanaged(key)
+		return r.handleUnmanaged(ctx, &ingress, plan.orphans)
+
+	case actionRetry:
+		log.Error(plan.tunnelErr, "Tunnel validation failed", "tunnel", plan.tunnelRef)
+		metrics.TunnelNotFoundTotal.Inc()
+		recordError(span, plan.tunnelErr)
 		// Requeue to retry
 		return ctrl.Result{Requeue: true}, nil
+
+	case actionWithdraw:
+		log.Info("Withdrawing PangolinResources", "reason", plan.reason)
+		if plan.tunnelErr != nil {
+			recordError(span, plan.tunnelErr)
+		}
+		return r.withdraw(ctx, &ingress, plan.orphans, plan.reason)
 	}
 
-	// Process all hosts in the Ingress
-	return r.processHosts(ctx, &ingress, tunnelName, tunnelNamespace)
+	metrics.SetIngressManaged(key, plan.class.className(&ingress), plan.target.name)
+	span.SetAttributes(attrTunnel.String(plan.target.namespace + "/" + plan.target.name))
+
+	if err := r.syncFinalizer(ctx, &ingress); err != nil {
+		log.Error(err, "Failed to update cleanup finalizer")
+		recordError(span, err)
+		return ctrl.Result{}, err
+	}
+
+	// Apply the hosts; requeue at the next schedule transition and the expiry
+	result, err := r.processHosts(ctx, plan)
+	if err != nil {
+		return result, err
+	}
+	requeueAt(&result, r.now(), plan.window.next)
+	if !plan.expired {
+		requeueAt(&result, r.now(), plan.expiry)
+	}
+	return result, nil
 }
 
-// processHosts processes all hosts in an Ingress, creating/updating PangolinResources.
-// It handles multi-host Ingresses by creating one PangolinResource per unique host.
-func (r *IngressReconciler) processHosts(
-	ctx context.Context,
-	ingress *networkingv1.Ingress,
-	tunnelName string,
-	tunnelNamespace string,
-) (ctrl.Result, error) {
-	log := r.Log.WithValues("ingress", types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace})
+// recordEvents records the planned events on the Ingress identified by key.
+// An event with a state is skipped while its state is the one last reported;
+// a state event that is no longer planned is forgotten, so that it is
+// recorded again when the state returns.
+func (r *IngressReconciler) recordEvents(ingress *networkingv1.Ingress, key string, events []plannedEvent) {
+	r.statesMu.Lock()
+	defer r.statesMu.Unlock()
+	if r.states == nil {
+		r.states = make(map[string]string)
+	}
+
+	planned := make(map[string]bool, len(stateReasons))
+	for _, e := range events {
+		if e.state != "" {
+			planned[e.reason] = true
+			stateKey := key + "/" + e.reason
+			if r.states[stateKey] == e.state {
+				continue
+			}
+			r.states[stateKey] = e.state
+		}
+		r.Recorder.Event(ingress, e.eventType, e.reason, e.message)
+	}
+	for _, reason := range stateReasons {
+		if !planned[reason] {
+			delete(r.states, key+"/"+reason)
+		}
+	}
+}
 
-	// Check for empty rules
-	if len(ingress.Spec.Rules) == 0 {
-		log.Info("Ingress has no rules, skipping")
-		r.Recorder.Event(ingress, corev1.EventTypeWarning, "NoRules", "Ingress has no rules defined")
-		return ctrl.Result{}, nil
+// recordState records a state event the plan does not know of, skipped
+// while its state is the one last reported. It is only forgotten with the
+// Ingress.
+func (r *IngressReconciler) recordState(ingress *networkingv1.Ingress, e plannedEvent) {
+	r.statesMu.Lock()
+	defer r.statesMu.Unlock()
+	if r.states == nil {
+		r.states = make(map[string]string)
 	}
 
-	// Collect and deduplicate hosts with their paths
-	hostGroups := r.collectHostPaths(ingress)
+	stateKey := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}.String() + "/" + e.reason
+	if r.states[stateKey] == e.state {
+		return
+	}
+	r.states[stateKey] = e.state
+	r.Recorder.Event(ingress, e.eventType, e.reason, e.message)
+}
 
-	if len(hostGroups) == 0 {
-		log.Info("Ingress has no valid hosts after filtering")
-		return ctrl.Result{}, nil
+// forgetStates drops the states reported for the deleted Ingress identified
+// by key.
+func (r *IngressReconciler) forgetStates(key string) {
+	r.statesMu.Lock()
+	defer r.statesMu.Unlock()
+	for stateKey := range r.states {
+		if strings.HasPrefix(stateKey, key+"/") {
+			delete(r.states, stateKey)
+		}
 	}
+}
 
-	// Track which PangolinResource names we create/update for orphan cleanup
-	desiredNames := make(map[string]bool)
+// processHosts carries out the plan of every host, creating or updating one
+// PangolinResource per unique host, and deletes the orphaned resources.
+func (r *IngressReconciler) processHosts(ctx context.Context, plan *ingressPlan) (ctrl.Result, error) {
+	ingress := plan.ingress
+	log := r.Log.WithValues("ingress", types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace})
+
+	ctx, span := tracer.Start(ctx, "processHosts", trace.WithAttributes(ingressAttributes(ingress)...))
+	defer span.End()
+	span.SetAttributes(attribute.Int("pic.host_count", len(plan.hosts)))
+
+	var result ctrl.Result
 
 	// Collect errors from processing hosts so we can continue with all hosts
 	// and still attempt orphan cleanup even if some hosts fail
 	var hostErrors []error
 
-	// Process each host
-	for _, group := range hostGroups {
-		// Build desired PangolinResource for this host
-		desired, err := r.buildDesiredPangolinResource(ingress, group.Host, group.Paths, tunnelName, tunnelNamespace)
-		if err != nil {
-			log.Error(err, "Failed to build desired PangolinResource", "host", group.Host)
-			r.Recorder.Event(ingress, corev1.EventTypeWarning, "InvalidHost",
-				fmt.Sprintf("Host %q: %s", group.Host, err.Error()))
+	for _, host := range plan.hosts {
+		if host.Err != nil {
+			log.Info("Skipping host", "host", host.Host, "reason", host.Err.Error())
+			if host.collision == nil {
+				metrics.HostValidationFailuresTotal.WithLabelValues(hostErrorReason(host.Err)).Inc()
+			}
 			continue // Skip this host, try others
 		}
-
-		// Set owner reference for garbage collection
-		if err := ctrl.SetControllerReference(ingress, desired, r.Scheme); err != nil {
-			log.Error(err, "Failed to set owner reference", "host", group.Host)
-			hostErrors = append(hostErrors, fmt.Errorf("host %q: failed to set owner reference: %w", group.Host, err))
-			continue // Continue processing other hosts
+		if host.adopt {
+			log.Info("Adopting PangolinResource", "resource", host.Resource.Name, "host", host.Host)
 		}
 
-		desiredNames[desired.Name] = true
-
 		// Create or update PangolinResource
-		if _, err := r.reconcilePangolinResource(ctx, ingress, desired); err != nil {
-			log.Error(err, "Failed to reconcile PangolinResource", "host", group.Host)
-			hostErrors = append(hostErrors, fmt.Errorf("host %q: failed to reconcile PangolinResource: %w", group.Host, err))
+		if _, err := r.reconcilePangolinResource(ctx, ingress, host.Resource, plan.policy); err != nil {
+			log.Error(err, "Failed to reconcile PangolinResource", "host", host.Host)
+			hostErrors = append(hostErrors, fmt.Errorf("host %q: failed to reconcile PangolinResource: %w", host.Host, err))
 			continue // Continue processing other hosts
 		}
+
+		if host.renameErr != nil {
+			log.Info("Renamed PangolinResource stalled, keeping the resources it replaces",
+				"host", host.Host, "reason", host.renameErr.Error())
+		} else if len(host.predecessors) > 0 {
+			waiting, err := r.replacePredecessors(ctx, ingress, host.Resource, host.predecessors)
+			if err != nil {
+				log.Error(err, "Failed to replace renamed PangolinResources", "host", host.Host)
+				hostErrors = append(hostErrors, fmt.Errorf("host %q: %w", host.Host, err))
+			}
+			if waiting {
+				// The status update of the new resource requeues the Ingress; poll in case it does not
+				result.RequeueAfter = renamePollInterval
+			}
+		}
 	}
 
 	// Always attempt orphan cleanup even if some hosts failed to process
-	if err := r.cleanupOrphanedResources(ctx, ingress, desiredNames); err != nil {
+	if err := r.deleteResources(ctx, ingress, plan.orphans, "host removed"); err != nil {
 		log.Error(err, "Failed to cleanup orphaned resources")
 		hostErrors = append(hostErrors, fmt.Errorf("failed to cleanup orphaned resources: %w", err))
 	}
 
 	// Return aggregate error if any hosts failed
 	if len(hostErrors) > 0 {
-		return ctrl.Result{}, errors.Join(hostErrors...)
+		err := errors.Join(hostErrors...)
+		recordError(span, err)
+		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return result, nil
+}
+
+// hostErrorReason maps a buildDesiredPangolinResource error to a metric label.
+func hostErrorReason(err error) string {
+	switch {
+	case errors.Is(err, util.ErrWildcardHost):
+		return "wildcard_host"
+	case errors.Is(err, util.ErrIPAddress):
+		return "ip_address"
+	case errors.Is(err, util.ErrInvalidHost):
+		return "invalid_host"
+	case errors.Is(err, errNoBackends):
+		return "no_backends"
+	case errors.Is(err, errDomainNotAllowed):
+		return "domain_not_allowed"
+	case errors.Is(err, errPolicyViolation):
+		return "policy_violation"
+	default:
+		return "other"
+	}
 }
 
 // collectHostPaths groups all paths by host, deduplicating hosts that appear in multiple rules.
-// Empty hosts are skipped with a warning event.
-func (r *IngressReconciler) collectHostPaths(ingress *networkingv1.Ingress) []HostPathGroup {
+// Empty hosts are skipped.
+func collectHostPaths(ingress *networkingv1.Ingress) []HostPathGroup {
 	hostMap := make(map[string][]networkingv1.HTTPIngressPath)
 
 	for _, rule := range ingress.Spec.Rules {
 		// Skip empty hosts (FR-007)
 		if rule.Host == "" {
-			r.Recorder.Event(ingress, corev1.EventTypeWarning, "EmptyHost",
-				"Rule with empty host skipped")
 			continue
 		}
 
@@ -238,109 +423,137 @@ func (r *IngressReconciler) collectHostPaths(ingress *networkingv1.Ingress) []Ho
 	return groups
 }
 
-// cleanupOrphanedResources deletes PangolinResources that are no longer needed.
-// This happens when a host is removed from an Ingress.
-func (r *IngressReconciler) cleanupOrphanedResources(
+// deleteResources deletes PangolinResources of the Ingress that are no
+// longer needed: a host was removed from the Ingress or may no longer be
+// exposed. reason is reported in the Deleted events.
+func (r *IngressReconciler) deleteResources(
 	ctx context.Context,
 	ingress *networkingv1.Ingress,
-	desiredNames map[string]bool,
+	resources []*pangolincrd.PangolinResource,
+	reason string,
 ) error {
 	log := r.Log.WithValues("ingress", types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace})
 
-	// List all PangolinResources owned by this Ingress
-	var resourceList pangolincrd.PangolinResourceList
-	if err := r.List(ctx, &resourceList,
-		client.InNamespace(ingress.Namespace),
-		client.MatchingLabels{LabelIngressUID: string(ingress.UID)},
-	); err != nil {
-		return fmt.Errorf("failed to list PangolinResources: %w", err)
-	}
+	ctx, span := tracer.Start(ctx, "cleanupOrphanedResources", trace.WithAttributes(ingressAttributes(ingress)...))
+	defer span.End()
 
-	// Delete resources that are no longer desired
-	for _, resource := range resourceList.Items {
-		if !desiredNames[resource.Name] {
-			log.Info("Deleting orphaned PangolinResource", "resource", resource.Name)
-			if err := r.Delete(ctx, &resource); err != nil && !apierrors.IsNotFound(err) {
-				return fmt.Errorf("failed to delete orphaned resource %s: %w", resource.Name, err)
-			}
-			r.Recorder.Event(ingress, corev1.EventTypeNormal, "Deleted",
-				fmt.Sprintf("Deleted PangolinResource %s (host removed)", resource.Name))
+	for _, resource := range resources {
+		log.Info("Deleting PangolinResource", "resource", resource.Name, "reason", reason)
+		span.AddEvent("delete", trace.WithAttributes(attrResource.String(resource.Name)))
+		if err := r.resources().Delete(ctx, resource); err != nil && !apierrors.IsNotFound(err) {
+			err = fmt.Errorf("failed to delete PangolinResource %s: %w", resource.Name, err)
+			recordError(span, err)
+			return err
 		}
+		r.Recorder.Event(ingress, corev1.EventTypeNormal, "Deleted",
+			fmt.Sprintf("Deleted PangolinResource %s (%s)", resource.Name, reason))
+		metrics.ResourceOperationsTotal.WithLabelValues(metrics.OperationDelete).Inc()
+		metrics.StopReadyTimer(resource.Namespace + "/" + resource.Name)
 	}
 
 	return nil
 }
 
 // isManaged checks if the Ingress should be managed by PIC.
-func (r *IngressReconciler) isManaged(ingress *networkingv1.Ingress) bool {
+func (r *IngressReconciler) isManaged(ingress *networkingv1.Ingress, class *IngressClassSettings) bool {
+	managed, _ := r.managedReason(ingress, class)
+	return managed
+}
+
+// managedReason reports whether the Ingress is managed by PIC and why.
+// An existing IngressClass is authoritative through its spec.controller;
+// without one, the "pangolin" and "pangolin-" class name conventions apply.
+func (r *IngressReconciler) managedReason(ingress *networkingv1.Ingress, class *IngressClassSettings) (bool, string) {
 	// Check enabled annotation
 	if enabled, ok := ingress.Annotations[AnnotationEnabled]; ok {
 		if strings.ToLower(enabled) == "false" {
-			return false
+			return false, fmt.Sprintf("annotation %s is %q", AnnotationEnabled, enabled)
 		}
 	}
 
-	// Check ingressClassName
-	className := ""
-	if ingress.Spec.IngressClassName != nil {
-		className = *ingress.Spec.IngressClassName
+	// Check ingressClassName, the legacy annotation or the default class
+	className := class.className(ingress)
+
+	if class != nil && class.Class != nil {
+		controller := class.Class.Spec.Controller
+		reason := fmt.Sprintf("IngressClass %q from %s has controller %q", className, class.Source, controller)
+		return controller == ControllerName, reason
 	}
 
-	return className == IngressClassPangolin || strings.HasPrefix(className, IngressClassPrefix)
+	switch {
+	case className == IngressClassPangolin:
+		return true, fmt.Sprintf("ingressClassName is %q", IngressClassPangolin)
+	case strings.HasPrefix(className, IngressClassPrefix):
+		return true, fmt.Sprintf("ingressClassName %q has prefix %q", className, IngressClassPrefix)
+	case className == "":
+		return false, "no ingressClassName set and no default IngressClass"
+	default:
+		return false, fmt.Sprintf("ingressClassName %q is not a pangolin class", className)
+	}
 }
 
 // resolveTunnel determines the tunnel name from the Ingress.
-func (r *IngressReconciler) resolveTunnel(ingress *networkingv1.Ingress) (string, error) {
+func (r *IngressReconciler) resolveTunnel(ingress *networkingv1.Ingress, class *IngressClassSettings) (string, error) {
+	tunnelName, _, err := r.resolveTunnelSource(ingress, class, nil)
+	return tunnelName, err
+}
+
+// resolveTunnelSource determines the tunnel name from the Ingress and
+// describes where it came from. The namespace's default tunnel, if any,
+// replaces the controller's default tunnel.
+func (r *IngressReconciler) resolveTunnelSource(
+	ingress *networkingv1.Ingress,
+	class *IngressClassSettings,
+	defaults *NamespaceDefaults,
+) (string, string, error) {
 	// Check annotation override
 	if tunnelName, ok := ingress.Annotations[AnnotationTunnelName]; ok && tunnelName != "" {
-		return tunnelName, nil
+		return tunnelName, fmt.Sprintf("annotation %s", AnnotationTunnelName), nil
 	}
 
-	// Get ingress class
-	className := ""
-	if ingress.Spec.IngressClassName != nil {
-		className = *ingress.Spec.IngressClassName
+	// Class parameters
+	if spec := class.configSpec(); spec != nil && spec.TunnelName != "" {
+		return spec.TunnelName, class.ConfigRef(), nil
 	}
 
-	// Default class -> default tunnel
+	// Get ingress class
+	className := class.className(ingress)
+
+	// Default class -> namespace or controller default tunnel
 	if className == IngressClassPangolin {
-		return r.Config.DefaultTunnelName, nil
+		if tunnelName, source := defaults.tunnel(); tunnelName != "" {
+			return tunnelName, source, nil
+		}
+		return r.config().DefaultTunnelName, "default tunnel for ingressClassName " + className, nil
 	}
 
 	// Multi-tunnel class -> lookup in mapping
 	if strings.HasPrefix(className, IngressClassPrefix) {
 		suffix := strings.TrimPrefix(className, IngressClassPrefix)
-		if tunnelName, ok := r.Config.TunnelMapping[suffix]; ok {
-			return tunnelName, nil
+		if tunnelName, ok := r.config().TunnelMapping[suffix]; ok {
+			return tunnelName, fmt.Sprintf("tunnel mapping for class suffix %q", suffix), nil
 		}
 		// If no mapping, use suffix as tunnel name
-		return suffix, nil
+		return suffix, fmt.Sprintf("suffix of ingressClassName %s (no tunnel mapping)", className), nil
 	}
 
-	return "", fmt.Errorf("cannot resolve tunnel for ingressClassName %q", className)
-}
-
-// validateTunnel checks if the PangolinTunnel exists in any namespace.
-// Returns the namespace where the tunnel was found.
-func (r *IngressReconciler) validateTunnel(ctx context.Context, tunnelName string) (string, error) {
-	var tunnelList pangolincrd.PangolinTunnelList
-	if err := r.List(ctx, &tunnelList); err != nil {
-		return "", fmt.Errorf("failed to list tunnels: %w", err)
-	}
-
-	for _, tunnel := range tunnelList.Items {
-		if tunnel.Name == tunnelName {
-			return tunnel.Namespace, nil
+	// Any other class with our controller -> namespace or controller default tunnel
+	if class != nil && class.Class != nil && class.Class.Spec.Controller == ControllerName {
+		if tunnelName, source := defaults.tunnel(); tunnelName != "" {
+			return tunnelName, source, nil
 		}
+		return r.config().DefaultTunnelName, "default tunnel for IngressClass " + className, nil
 	}
 
-	return "", fmt.Errorf("tunnel %q not found", tunnelName)
+	return "", "", fmt.Errorf("cannot resolve tunnel for ingressClassName %q", className)
 }
 
 // buildDesiredPangolinResource creates the desired PangolinResource spec.
 // It accepts the host and its associated paths (already collected and deduplicated).
+// Class parameters supply defaults that Ingress annotations override.
 func (r *IngressReconciler) buildDesiredPangolinResource(
 	ingress *networkingv1.Ingress,
+	class *IngressClassSettings,
 	host string,
 	paths []networkingv1.HTTPIngressPath,
 	tunnelName string,
@@ -360,6 +573,23 @@ func (r *IngressReconciler) buildDesiredPangolinResource(
 		subdomain = override
 	}
 
+	classSpec := class.configSpec()
+	if classSpec != nil {
+		fqdn := domain
+		if subdomain != "" {
+			fqdn = subdomain + "." + domain
+		}
+		if !domainAllowed(fqdn, classSpec.AllowedDomains) {
+			return nil, fmt.Errorf("%w: %q is not in %s", errDomainNotAllowed, fqdn,
+				strings.Join(classSpec.AllowedDomains, ", "))
+		}
+	}
+
+	backendScheme := r.config().BackendScheme
+	if classSpec != nil && classSpec.BackendScheme != "" {
+		backendScheme = classSpec.BackendScheme
+	}
+
 	// Build targets from the provided paths for this host
 	var targets []pangolincrd.Target
 
@@ -398,7 +628,7 @@ func (r *IngressReconciler) buildDesiredPangolinResource(
 		target := pangolincrd.Target{
 			IP:            backendHost,
 			Port:          backendPort,
-			Method:        r.Config.BackendScheme,
+			Method:        backendScheme,
 			Path:          path.Path,
 			PathMatchType: pathMatchType,
 			Priority:      priority,
@@ -407,15 +637,19 @@ func (r *IngressReconciler) buildDesiredPangolinResource(
 	}
 
 	if len(targets) == 0 {
-		return nil, fmt.Errorf("no valid backends found in Ingress paths")
+		return nil, errNoBackends
 	}
 
-	// Generate deterministic name
-	name := util.GenerateName(ingress.Namespace, ingress.Name, host)
+	// Generate deterministic name, qualified by the cluster in multi-cluster mode
+	cluster := r.clusterName()
+	name := util.GenerateClusterName(cluster, ingress.Namespace, ingress.Name, host)
 
 	// Generate display name for Pangolin UI
 	// Must be unique per host since Pangolin uses this as resource identifier
 	displayName := fmt.Sprintf("%s/%s/%s", ingress.Namespace, ingress.Name, host)
+	if cluster != "" {
+		displayName = cluster + "/" + displayName
+	}
 
 	// Protocol is always "http" for Ingress resources
 	// Pangolin handles TLS termination automatically
@@ -424,23 +658,49 @@ func (r *IngressReconciler) buildDesiredPangolinResource(
 	_ = ingress.Spec.TLS // TLS config available for future use if needed
 
 	// Parse authentication annotations
-	// By default: SSO disabled, access allowed (no blocking)
-	ssoEnabled := ingress.Annotations[AnnotationSSO] == "true"
-	blockAccess := ingress.Annotations[AnnotationBlockAccess] == "true"
+	// By default: SSO disabled, access allowed (no blocking), unless the
+	// class parameters say otherwise
+	var ssoEnabled, blockAccess bool
+	if classSpec != nil && classSpec.SSO != nil {
+		ssoEnabled = *classSpec.SSO
+	}
+	if classSpec != nil && classSpec.BlockAccess != nil {
+		blockAccess = *classSpec.BlockAccess
+	}
+	if value, ok := ingress.Annotations[AnnotationSSO]; ok {
+		ssoEnabled = value == "true"
+	}
+	if value, ok := ingress.Annotations[AnnotationBlockAccess]; ok {
+		blockAccess = value == "true"
+	}
+
+	// A disabled Ingress keeps its resources, turned off in Pangolin
+	enabled := !isDisabled(ingress)
+
+	labels := map[string]string{
+		LabelIngressUID:       string(ingress.UID),
+		LabelIngressName:      ingress.Name,
+		LabelIngressNamespace: ingress.Namespace,
+	}
+	if id := r.config().InstanceID; id != "" {
+		labels[LabelInstance] = id
+	}
+	if cluster != "" {
+		labels[LabelCluster] = cluster
+	}
 
 	return &pangolincrd.PangolinResource{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: ingress.Namespace,
-			Labels: map[string]string{
-				LabelIngressUID:       string(ingress.UID),
-				LabelIngressName:      ingress.Name,
-				LabelIngressNamespace: ingress.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				AnnotationHost: host,
 			},
 		},
 		Spec: pangolincrd.PangolinResourceSpec{
 			Name:     displayName,
-			Enabled:  true,
+			Enabled:  enabled,
 			Protocol: protocol,
 			TunnelRef: pangolincrd.TunnelRef{
 				Name:      tunnelName,
@@ -457,135 +717,263 @@ func (r *IngressReconciler) buildDesiredPangolinResource(
 	}, nil
 }
 
-// reconcilePangolinResource creates or updates the PangolinResource.
+// reconcilePangolinResource creates or updates the PangolinResource using
+// server-side apply. PIC owns every field it sets (spec, labels, annotations
+// and owner references); fields it stops setting are removed by the API server
+// and fields added by other actors are left untouched. Manual changes that
+// would break the cluster policy are reverted even when they are allowed.
 func (r *IngressReconciler) reconcilePangolinResource(
 	ctx context.Context,
 	ingress *networkingv1.Ingress,
 	desired *pangolincrd.PangolinResource,
+	policy *namespacePolicy,
 ) (ctrl.Result, error) {
 	log := r.Log.WithValues(
 		"ingress", types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace},
 		"pangolinresource", desired.Name,
 	)
 
-	// Check if exists
+	ctx, span := tracer.Start(ctx, "reconcilePangolinResource", trace.WithAttributes(
+		append(ingressAttributes(ingress),
+			attrHost.String(resourceHost(desired)),
+			attrResource.String(desired.Name),
+		)...,
+	))
+	defer span.End()
+
+	// Fetch the current object to tell creates from updates
 	var existing pangolincrd.PangolinResource
-	err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, &existing)
+	err := r.resources().Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, &existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Error(err, "Failed to get existing PangolinResource")
+		recordError(span, err)
+		return ctrl.Result{}, err
+	}
+	exists := err == nil
 
-	if apierrors.IsNotFound(err) {
-		// Create new resource
-		log.Info("Creating PangolinResource")
-		if err := r.Create(ctx, desired); err != nil {
-			log.Error(err, "Failed to create PangolinResource")
-			return ctrl.Result{}, err
+	// A resource another PIC instance created is left to that instance
+	if exists {
+		if id, foreign := r.foreignInstance(&existing); foreign {
+			log.Info("PangolinResource belongs to another PIC instance, leaving it unchanged", "instance", id)
+			r.Recorder.Event(ingress, corev1.EventTypeWarning, "OwnedByOtherInstance",
+				fmt.Sprintf("PangolinResource %s was created by PIC instance %q; leaving it unchanged", existing.Name, id))
+			return ctrl.Result{}, nil
 		}
-		r.Recorder.Event(ingress, corev1.EventTypeNormal, "Created",
-			fmt.Sprintf("Created PangolinResource %s", desired.Name))
-		return ctrl.Result{}, nil
 	}
 
+	// Record what the Ingress renders, to tell fields removed by hand from
+	// fields the Ingress no longer sets
+	hash, err := renderedHash(desired)
 	if err != nil {
-		log.Error(err, "Failed to get existing PangolinResource")
+		log.Error(err, "Failed to hash PangolinResource")
+		recordError(span, err)
 		return ctrl.Result{}, err
 	}
+	rendered := exists && existing.Annotations[AnnotationRenderedHash] == hash
+	setAnnotation(desired, AnnotationRenderedHash, hash)
 
-	// Update if changed
-	if r.specChanged(&existing.Spec, &desired.Spec) {
-		log.Info("Updating PangolinResource")
-		existing.Spec = desired.Spec
-		if err := r.Update(ctx, &existing); err != nil {
-			log.Error(err, "Failed to update PangolinResource")
-			return ctrl.Result{}, err
+	// A diff on a field another field manager owns, or on a field removed
+	// since PIC applied it, is drift rather than a change coming from the
+	// Ingress. A resource without a controller is being adopted; its
+	// differences are not drift.
+	var drift []string
+	if _, controlled := controllerUID(&existing); exists && controlled {
+		diffs, err := detectDrift(&existing, desired)
+		if err != nil {
+			log.Error(err, "Failed to compute drift")
+		}
+		if changes := foreignDrift(&existing, diffs, rendered); len(changes) > 0 {
+			allowed := allowsManualChanges(ingress, &existing)
+			if allowed {
+				// Keep the manual changes and apply everything else, unless
+				// they break the cluster policy
+				kept := desired.DeepCopy()
+				if err := keepManualChanges(kept, &existing, changes); err != nil {
+					log.Error(err, "Failed to keep manual changes to PangolinResource")
+					recordError(span, err)
+					return ctrl.Result{}, err
+				}
+				if err := policy.check(kept); err != nil {
+					allowed = false
+					log.Info("Manual changes break a cluster policy, reverting them", "reason", err.Error())
+					r.Recorder.Event(ingress, corev1.EventTypeWarning, "PolicyViolation",
+						fmt.Sprintf("PangolinResource %s: manual changes reverted: %s", desired.Name, err.Error()))
+				} else {
+					*desired = *kept
+					log.V(1).Info("Keeping manual changes to PangolinResource", "diff", driftDiffs(changes, true))
+				}
+			}
+			if !allowed {
+				if drift = driftDiffs(changes, false); len(drift) > 0 {
+					managers := driftManagers(changes)
+					log.Info("Drift detected, reverting manual changes", "managers", managers, "diff", drift)
+					changedBy := ""
+					if len(managers) > 0 {
+						changedBy = " by " + strings.Join(managers, ", ")
+					}
+					r.Recorder.Event(ingress, corev1.EventTypeWarning, "DriftDetected",
+						fmt.Sprintf("PangolinResource %s was changed%s: %s",
+							desired.Name, changedBy, formatDrift(drift)))
+				}
+			}
 		}
-		r.Recorder.Event(ingress, corev1.EventTypeNormal, "Updated",
-			fmt.Sprintf("Updated PangolinResource %s", desired.Name))
 	}
 
-	return ctrl.Result{}, nil
-}
-
-// specChanged compares two PangolinResourceSpecs.
-func (r *IngressReconciler) specChanged(current, desired *pangolincrd.PangolinResourceSpec) bool {
-	if current.Name != desired.Name {
-		return true
-	}
-	if current.Enabled != desired.Enabled {
-		return true
-	}
-	if current.Protocol != desired.Protocol {
-		return true
+	// Propagate the trace to pangolin-operator through an annotation
+	if exists {
+		setTraceParent(ctx, desired, &existing)
+	} else {
+		setTraceParent(ctx, desired, nil)
 	}
-	if current.TunnelRef.Name != desired.TunnelRef.Name {
-		return true
-	}
-	if current.TunnelRef.Namespace != desired.TunnelRef.Namespace {
-		return true
-	}
-	if current.HTTPConfig != nil && desired.HTTPConfig != nil {
-		if current.HTTPConfig.DomainName != desired.HTTPConfig.DomainName {
-			return true
-		}
-		if current.HTTPConfig.Subdomain != desired.HTTPConfig.Subdomain {
-			return true
-		}
-		if current.HTTPConfig.SSO != desired.HTTPConfig.SSO {
-			return true
-		}
-		if current.HTTPConfig.BlockAccess != desired.HTTPConfig.BlockAccess {
-			return true
+
+	// Apply the desired state; ForceOwnership takes back fields another
+	// manager has modified. A no-op apply leaves the resourceVersion unchanged.
+	desired.SetGroupVersionKind(pangolincrd.GroupVersion.WithKind("PangolinResource"))
+	if err := r.resources().Patch(ctx, desired, client.Apply,
+		client.FieldOwner(FieldManager),
+		client.ForceOwnership,
+	); err != nil {
+		if namespace, missing := r.missingNamespace(err); missing {
+			err = fmt.Errorf("namespace %s does not exist in the management cluster, create it there: %w", namespace, err)
+			r.Recorder.Event(ingress, corev1.EventTypeWarning, "ManagementNamespaceMissing",
+				fmt.Sprintf("PangolinResource %s cannot be written: %s", desired.Name, err.Error()))
 		}
+		log.Error(err, "Failed to apply PangolinResource")
+		recordError(span, err)
+		return ctrl.Result{}, err
 	}
-	// Compare targets arrays
-	if len(current.Targets) != len(desired.Targets) {
-		return true
+
+	if len(drift) > 0 {
+		metrics.DriftRepairsTotal.WithLabelValues(desired.Namespace).Inc()
 	}
-	for i := range current.Targets {
-		if i >= len(desired.Targets) {
-			return true
-		}
-		ct := current.Targets[i]
-		dt := desired.Targets[i]
-		if ct.IP != dt.IP || ct.Port != dt.Port || ct.Method != dt.Method ||
-			ct.Path != dt.Path || ct.PathMatchType != dt.PathMatchType || ct.Priority != dt.Priority {
-			return true
-		}
+
+	// Time changes until pangolin-operator reports the resource Ready
+	timerKey := desired.Namespace + "/" + desired.Name
+	switch {
+	case !exists:
+		log.Info("Created PangolinResource")
+		r.Recorder.Event(ingress, corev1.EventTypeNormal, "Created",
+			fmt.Sprintf("Created PangolinResource %s", desired.Name))
+		metrics.ResourceOperationsTotal.WithLabelValues(metrics.OperationCreate).Inc()
+		metrics.StartReadyTimer(timerKey)
+	case desired.ResourceVersion != existing.ResourceVersion:
+		log.Info("Updated PangolinResource")
+		r.Recorder.Event(ingress, corev1.EventTypeNormal, "Updated",
+			fmt.Sprintf("Updated PangolinResource %s", desired.Name))
+		metrics.ResourceOperationsTotal.WithLabelValues(metrics.OperationUpdate).Inc()
+		metrics.StartReadyTimer(timerKey)
+	case desired.Status.Phase == pangolincrd.PhaseReady:
+		metrics.ObserveReady(timerKey)
 	}
-	return false
+
+	return ctrl.Result{}, nil
 }
 
-// handleUnmanaged removes PangolinResource for unmanaged Ingress.
-func (r *IngressReconciler) handleUnmanaged(ctx context.Context, ingress *networkingv1.Ingress) (ctrl.Result, error) {
+// handleUnmanaged removes the PangolinResources of an unmanaged Ingress and
+// releases its finalizer.
+func (r *IngressReconciler) handleUnmanaged(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	resources []*pangolincrd.PangolinResource,
+) (ctrl.Result, error) {
 	log := r.Log.WithValues("ingress", types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace})
 
-	// Find any PangolinResources owned by this Ingress
-	var resourceList pangolincrd.PangolinResourceList
-	if err := r.List(ctx, &resourceList,
-		client.InNamespace(ingress.Namespace),
-		client.MatchingLabels{LabelIngressUID: string(ingress.UID)},
-	); err != nil {
-		log.Error(err, "Failed to list PangolinResources")
+	if err := r.deleteResources(ctx, ingress, resources, "Ingress no longer managed"); err != nil {
+		log.Error(err, "Failed to delete PangolinResources")
 		return ctrl.Result{}, err
 	}
 
-	for _, resource := range resourceList.Items {
-		log.Info("Deleting PangolinResource for unmanaged Ingress", "resource", resource.Name)
-		if err := r.Delete(ctx, &resource); err != nil && !apierrors.IsNotFound(err) {
-			log.Error(err, "Failed to delete PangolinResource")
-			return ctrl.Result{}, err
-		}
-		r.Recorder.Event(ingress, corev1.EventTypeNormal, "Deleted",
-			fmt.Sprintf("Deleted PangolinResource %s (Ingress no longer managed)", resource.Name))
+	// Nothing is left to wait for on deletion
+	if err := r.patchFinalizer(ctx, ingress, controllerutil.RemoveFinalizer); err != nil {
+		log.Error(err, "Failed to remove cleanup finalizer")
+		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
 }
 
-// SetupWithManager sets up the controller with the Manager.
+// withdraw deletes every PangolinResource of an Ingress that may no longer
+// be exposed, reporting reason in the Deleted events.
+func (r *IngressReconciler) withdraw(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	resources []*pangolincrd.PangolinResource,
+	reason string,
+) (ctrl.Result, error) {
+	metrics.SetIngressUnmanaged(types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}.String())
+	if err := r.deleteResources(ctx, ingress, resources, reason); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. In multi-cluster
+// mode it also starts the management cluster's cache and watches its
+// PangolinResources, PangolinTunnels and PangolinTunnelGrants.
 func (r *IngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&networkingv1.Ingress{}).
-		Owns(&pangolincrd.PangolinResource{}).
-		Complete(r)
+	indexer := mgr.GetFieldIndexer()
+	if r.Management != nil {
+		if err := mgr.Add(r.Management); err != nil {
+			return fmt.Errorf("failed to add management cluster: %w", err)
+		}
+		indexer = r.Management.GetFieldIndexer()
+	}
+	if !r.config().RequireTunnelGrants {
+		r.Log.Info("Tunnel grants are turned off (requireTunnelGrants: false), namespaces without PangolinTunnelGrants may be referenced from any namespace")
+	}
+	if err := metrics.RegisterResourceCollector(r.resources(), r.resourceSelector()...); err != nil {
+		return fmt.Errorf("failed to register metrics collector: %w", err)
+	}
+	if err := SetupIndexes(context.Background(), indexer); err != nil {
+		return err
+	}
+	if interval := r.config().OrphanSweepInterval; interval > 0 {
+		if err := mgr.Add(manager.RunnableFunc(r.sweepOrphansEvery(interval))); err != nil {
+			return fmt.Errorf("failed to add orphan sweep: %w", err)
+		}
+	}
+
+	if r.requeueAll == nil {
+		r.requeueAll = make(chan event.GenericEvent, 1)
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&networkingv1.Ingress{}, builder.WithPredicates(r.inShard())).
+		Watches(&networkingv1.IngressClass{},
+			handler.EnqueueRequestsFromMapFunc(r.ingressesForClass)).
+		Watches(&piccrd.PangolinIngressClassConfig{},
+			handler.EnqueueRequestsFromMapFunc(r.ingressesForClassConfig)).
+		Watches(&piccrd.PangolinNamespacedIngressClassConfig{},
+			handler.EnqueueRequestsFromMapFunc(r.ingressesForClassConfig)).
+		Watches(&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.ingressesInNamespace),
+			builder.WithPredicates(namespaceChanged)).
+		Watches(&piccrd.PangolinIngressPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.ingressesInNamespace)).
+		Watches(&piccrd.PangolinClusterPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.allIngresses)).
+		WatchesRawSource(&source.Channel{Source: r.requeueAll},
+			handler.EnqueueRequestsFromMapFunc(r.allIngresses))
+
+	if r.Management == nil {
+		b = b.Owns(&pangolincrd.PangolinResource{}).
+			Watches(&pangolincrd.PangolinTunnel{},
+				handler.EnqueueRequestsFromMapFunc(r.allIngresses),
+				builder.WithPredicates(tunnelSwitched)).
+			Watches(&piccrd.PangolinTunnelGrant{},
+				handler.EnqueueRequestsFromMapFunc(r.allIngresses))
+	} else {
+		// Resources in the management cluster carry labels, not owner
+		// references, naming their Ingress
+		b = b.WatchesRawSource(source.Kind(r.Management.GetCache(), &pangolincrd.PangolinResource{}),
+			handler.EnqueueRequestsFromMapFunc(r.ingressForResource)).
+			WatchesRawSource(source.Kind(r.Management.GetCache(), &pangolincrd.PangolinTunnel{}),
+				handler.EnqueueRequestsFromMapFunc(r.allIngresses),
+				builder.WithPredicates(tunnelSwitched)).
+			WatchesRawSource(source.Kind(r.Management.GetCache(), &piccrd.PangolinTunnelGrant{}),
+				handler.EnqueueRequestsFromMapFunc(r.allIngresses))
+	}
+	return b.Complete(r)
 }
 
 // NewIngressReconciler creates a new IngressReconciler.
@@ -597,10 +985,11 @@ func NewIngressReconciler(
 	recorder record.EventRecorder,
 ) *IngressReconciler {
 	return &IngressReconciler{
-		Client:   client,
-		Scheme:   scheme,
-		Config:   cfg,
-		Log:      log,
-		Recorder: recorder,
+		Client:     client,
+		Scheme:     scheme,
+		Config:     cfg,
+		Log:        log,
+		Recorder:   recorder,
+		requeueAll: make(chan event.GenericEvent, 1),
 	}
 }
@@ -0,0 +1,312 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
+)
+
+const (
+	// ControllerName is the IngressClass spec.controller value handled by PIC.
+	ControllerName = "pangolin.io/ingress-controller"
+
+	// AnnotationIngressClass is the legacy annotation selecting the class of
+	// an Ingress, used before spec.ingressClassName existed.
+	AnnotationIngressClass = "kubernetes.io/ingress.class"
+)
+
+// errDomainNotAllowed is returned when a host is outside the class's allowed domains.
+var errDomainNotAllowed = errors.New("domain not allowed by IngressClass")
+
+// IngressClassSettings is the IngressClass of an Ingress and its PIC
+// parameters, as read from the cluster. A nil *IngressClassSettings means the
+// class was not looked up and only the class name conventions apply.
+type IngressClassSettings struct {
+	// Name is the effective class name: spec.ingressClassName, the legacy
+	// annotation, or the cluster default class. Empty if the Ingress has none.
+	Name string
+
+	// Source describes where Name came from.
+	Source string
+
+	// Class is the IngressClass object, nil if it does not exist.
+	Class *networkingv1.IngressClass
+
+	// Config holds the settings of the PangolinIngressClassConfig or
+	// PangolinNamespacedIngressClassConfig referenced by the class
+	// parameters, nil if the class has none. Its namespace is set for a
+	// PangolinNamespacedIngressClassConfig.
+	Config *piccrd.PangolinIngressClassConfig
+}
+
+// ConfigRef describes the config referenced by the class parameters, as
+// "<kind> <name>" or "<kind> <namespace>/<name>", or "" if there is none.
+func (s *IngressClassSettings) ConfigRef() string {
+	switch {
+	case s == nil || s.Config == nil:
+		return ""
+	case s.Config.Namespace != "":
+		return fmt.Sprintf("%s %s/%s", piccrd.KindPangolinNamespacedIngressClassConfig, s.Config.Namespace, s.Config.Name)
+	default:
+		return fmt.Sprintf("%s %s", piccrd.KindPangolinIngressClassConfig, s.Config.Name)
+	}
+}
+
+// configSpec returns the class parameters, nil-safe.
+func (s *IngressClassSettings) configSpec() *piccrd.PangolinIngressClassConfigSpec {
+	if s == nil || s.Config == nil {
+		return nil
+	}
+	return &s.Config.Spec
+}
+
+// className returns the effective class name of the Ingress. Without
+// settings the cluster default class is unknown and only the Ingress itself
+// is consulted.
+func (s *IngressClassSettings) className(ingress *networkingv1.Ingress) string {
+	if s != nil {
+		return s.Name
+	}
+	name, _ := ingressClassName(ingress)
+	return name
+}
+
+// ingressClassName returns the class set on the Ingress, from
+// spec.ingressClassName or the legacy annotation, and where it came from.
+func ingressClassName(ingress *networkingv1.Ingress) (string, string) {
+	if ingress.Spec.IngressClassName != nil && *ingress.Spec.IngressClassName != "" {
+		return *ingress.Spec.IngressClassName, "spec.ingressClassName"
+	}
+	if name := ingress.Annotations[AnnotationIngressClass]; name != "" {
+		return name, "annotation " + AnnotationIngressClass
+	}
+	return "", ""
+}
+
+// isDefaultClass reports whether the IngressClass is marked as the cluster default.
+func isDefaultClass(class *networkingv1.IngressClass) bool {
+	return class.Annotations[networkingv1.AnnotationIsDefaultIngressClass] == "true"
+}
+
+// resolveIngressClass reads the IngressClass of the Ingress and, if its
+// parameters reference a PangolinIngressClassConfig, that config. An Ingress
+// without a class gets the cluster default IngressClass, if there is one.
+// A missing IngressClass is not an error; a missing or misreferenced config is.
+func (r *IngressReconciler) resolveIngressClass(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+) (*IngressClassSettings, error) {
+	settings := &IngressClassSettings{}
+	settings.Name, settings.Source = ingressClassName(ingress)
+
+	if settings.Name == "" {
+		class, err := r.defaultIngressClass(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if class == nil {
+			return settings, nil
+		}
+		settings.Name, settings.Source = class.Name, "default IngressClass"
+		settings.Class = class
+	} else {
+		var class networkingv1.IngressClass
+		if err := r.Get(ctx, types.NamespacedName{Name: settings.Name}, &class); err != nil {
+			if apierrors.IsNotFound(err) {
+				return settings, nil
+			}
+			return nil, fmt.Errorf("failed to get IngressClass %q: %w", settings.Name, err)
+		}
+		settings.Class = &class
+	}
+
+	class := settings.Class
+	params := class.Spec.Parameters
+	if class.Spec.Controller != ControllerName || !referencesClassConfig(params) {
+		return settings, nil
+	}
+	cfg, err := r.getClassConfig(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("IngressClass %q: %w", settings.Name, err)
+	}
+	settings.Config = cfg
+
+	return settings, nil
+}
+
+// getClassConfig reads the config referenced by IngressClass parameters: a
+// PangolinIngressClassConfig with scope Cluster (the default), or a
+// PangolinNamespacedIngressClassConfig in the parameters namespace with
+// scope Namespace.
+func (r *IngressReconciler) getClassConfig(
+	ctx context.Context,
+	params *networkingv1.IngressClassParametersReference,
+) (*piccrd.PangolinIngressClassConfig, error) {
+	scope := networkingv1.IngressClassParametersReferenceScopeCluster
+	if params.Scope != nil {
+		scope = *params.Scope
+	}
+
+	switch {
+	case scope == networkingv1.IngressClassParametersReferenceScopeCluster &&
+		params.Kind == piccrd.KindPangolinIngressClassConfig:
+		var cfg piccrd.PangolinIngressClassConfig
+		if err := r.Get(ctx, types.NamespacedName{Name: params.Name}, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to get %s %q: %w", piccrd.KindPangolinIngressClassConfig, params.Name, err)
+		}
+		return &cfg, nil
+
+	case scope == networkingv1.IngressClassParametersReferenceScopeNamespace &&
+		params.Kind == piccrd.KindPangolinNamespacedIngressClassConfig:
+		if params.Namespace == nil || *params.Namespace == "" {
+			return nil, fmt.Errorf("parameters with scope %q must set a namespace", scope)
+		}
+		key := types.NamespacedName{Namespace: *params.Namespace, Name: params.Name}
+		var cfg piccrd.PangolinNamespacedIngressClassConfig
+		if err := r.Get(ctx, key, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to get %s %s: %w", piccrd.KindPangolinNamespacedIngressClassConfig, key, err)
+		}
+		return &piccrd.PangolinIngressClassConfig{ObjectMeta: cfg.ObjectMeta, Spec: cfg.Spec}, nil
+
+	default:
+		return nil, fmt.Errorf("%s is referenced with scope %q; use %s with scope %q or %s with scope %q",
+			params.Kind, scope,
+			piccrd.KindPangolinIngressClassConfig, networkingv1.IngressClassParametersReferenceScopeCluster,
+			piccrd.KindPangolinNamespacedIngressClassConfig, networkingv1.IngressClassParametersReferenceScopeNamespace)
+	}
+}
+
+// defaultIngressClass returns the IngressClass marked as the cluster default,
+// or nil if there is none. When several are marked, one handled by PIC is
+// preferred, then the first by name, so the choice is stable.
+func (r *IngressReconciler) defaultIngressClass(ctx context.Context) (*networkingv1.IngressClass, error) {
+	var classes networkingv1.IngressClassList
+	if err := r.List(ctx, &classes); err != nil {
+		return nil, fmt.Errorf("failed to list IngressClasses: %w", err)
+	}
+
+	var found *networkingv1.IngressClass
+	for i := range classes.Items {
+		class := &classes.Items[i]
+		if !isDefaultClass(class) {
+			continue
+		}
+		switch {
+		case found == nil,
+			class.Spec.Controller == ControllerName && found.Spec.Controller != ControllerName,
+			(class.Spec.Controller == ControllerName) == (found.Spec.Controller == ControllerName) && class.Name < found.Name:
+			found = class
+		}
+	}
+	return found, nil
+}
+
+// referencesClassConfig reports whether IngressClass parameters point at a
+// PangolinIngressClassConfig or a PangolinNamespacedIngressClassConfig.
+func referencesClassConfig(params *networkingv1.IngressClassParametersReference) bool {
+	return params != nil &&
+		params.APIGroup != nil && *params.APIGroup == piccrd.GroupName &&
+		(params.Kind == piccrd.KindPangolinIngressClassConfig ||
+			params.Kind == piccrd.KindPangolinNamespacedIngressClassConfig)
+}
+
+// referencesConfigObject reports whether IngressClass parameters point at
+// the given PangolinIngressClassConfig or PangolinNamespacedIngressClassConfig.
+func referencesConfigObject(params *networkingv1.IngressClassParametersReference, obj client.Object) bool {
+	if !referencesClassConfig(params) || params.Name != obj.GetName() {
+		return false
+	}
+	if _, namespaced := obj.(*piccrd.PangolinNamespacedIngressClassConfig); namespaced {
+		return params.Kind == piccrd.KindPangolinNamespacedIngressClassConfig &&
+			params.Namespace != nil && *params.Namespace == obj.GetNamespace()
+	}
+	return params.Kind == piccrd.KindPangolinIngressClassConfig
+}
+
+// domainAllowed reports whether host matches one of the allowed domains:
+// "example.com" matches the domain and its subdomains, "*.example.com" only
+// its subdomains. An empty list allows every host.
+func domainAllowed(host string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, domain := range allowed {
+		domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+		if wildcard := strings.TrimPrefix(domain, "*."); wildcard != domain {
+			if strings.HasSuffix(host, "."+wildcard) {
+				return true
+			}
+			continue
+		}
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// ingressesForClass enqueues the Ingresses of a changed IngressClass. Changes
+// to a PIC class may make it the default, or stop it being the default, so
+// Ingresses without a class are enqueued too.
+func (r *IngressReconciler) ingressesForClass(ctx context.Context, obj client.Object) []reconcile.Request {
+	names := map[string]bool{obj.GetName(): true}
+	if class, ok := obj.(*networkingv1.IngressClass); ok && class.Spec.Controller == ControllerName {
+		names[""] = true
+	}
+	return r.ingressesWithClass(ctx, names)
+}
+
+// ingressesForClassConfig enqueues the Ingresses of every IngressClass whose
+// parameters reference a changed PangolinIngressClassConfig or
+// PangolinNamespacedIngressClassConfig.
+func (r *IngressReconciler) ingressesForClassConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	var classes networkingv1.IngressClassList
+	if err := r.List(ctx, &classes); err != nil {
+		r.Log.Error(err, "Failed to list IngressClasses")
+		return nil
+	}
+
+	names := make(map[string]bool)
+	for _, class := range classes.Items {
+		if referencesConfigObject(class.Spec.Parameters, obj) {
+			names[class.Name] = true
+			if isDefaultClass(&class) {
+				names[""] = true
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return r.ingressesWithClass(ctx, names)
+}
+
+// ingressesWithClass returns a request for every Ingress whose class, from
+// spec.ingressClassName or the legacy annotation, is in classNames.
+func (r *IngressReconciler) ingressesWithClass(ctx context.Context, classNames map[string]bool) []reconcile.Request {
+	var ingresses networkingv1.IngressList
+	if err := r.List(ctx, &ingresses); err != nil {
+		r.Log.Error(err, "Failed to list Ingresses")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ingress := range ingresses.Items {
+		if name, _ := ingressClassName(&ingress); classNames[name] {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name},
+			})
+		}
+	}
+	return requests
+}
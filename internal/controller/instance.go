@@ -0,0 +1,41 @@
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// LabelInstance records the instanceID of the PIC installation that created
+// a PangolinResource. Another installation managing the same Ingress class
+// leaves the resource alone instead of fighting over it.
+const LabelInstance = "pic.ingress.k8s.io/instance"
+
+// foreignInstance returns the instance ID a PangolinResource is labeled
+// with when it is not this instance's. Resources without the label predate
+// instance IDs and belong to whichever instance manages their Ingress.
+func (r *IngressReconciler) foreignInstance(obj metav1.Object) (string, bool) {
+	id, ok := obj.GetLabels()[LabelInstance]
+	if !ok || id == r.config().InstanceID {
+		return "", false
+	}
+	return id, true
+}
+
+// isForeign reports whether the resource was created by another instance.
+func (r *IngressReconciler) isForeign(obj metav1.Object) bool {
+	_, foreign := r.foreignInstance(obj)
+	return foreign
+}
+
+// ownResources returns the resources not created by another instance, or
+// for another cluster's Ingresses.
+func (r *IngressReconciler) ownResources(resources []pangolincrd.PangolinResource) []pangolincrd.PangolinResource {
+	own := make([]pangolincrd.PangolinResource, 0, len(resources))
+	for _, resource := range resources {
+		if _, foreign := r.foreignInstance(&resource); !foreign && resource.Labels[LabelCluster] == r.clusterName() {
+			own = append(own, resource)
+		}
+	}
+	return own
+}
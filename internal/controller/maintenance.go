@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// AnnotationPaused set to "true" on an Ingress stops PIC from creating,
+	// changing or deleting its PangolinResources until it is removed.
+	AnnotationPaused = "pangolin.ingress.k8s.io/paused"
+
+	// AnnotationDisabled set to "true" keeps the PangolinResources but turns
+	// them off in Pangolin. On a PangolinTunnel it disables the resources of
+	// every Ingress using the tunnel.
+	AnnotationDisabled = "pangolin.ingress.k8s.io/disabled"
+)
+
+// isPaused reports whether the Ingress carries the paused annotation.
+func isPaused(obj metav1.Object) bool {
+	return strings.ToLower(obj.GetAnnotations()[AnnotationPaused]) == "true"
+}
+
+// isDisabled reports whether the object carries the disabled annotation.
+func isDisabled(obj metav1.Object) bool {
+	return strings.ToLower(obj.GetAnnotations()[AnnotationDisabled]) == "true"
+}
+
+// disabledReason explains why the resources of an Ingress using the tunnel
+// are disabled, or returns "" if they are not.
+func disabledReason(ingress metav1.Object, target tunnelTarget) string {
+	switch {
+	case isDisabled(ingress):
+		return fmt.Sprintf("annotation %s on the Ingress", AnnotationDisabled)
+	case target.disabled:
+		return fmt.Sprintf("annotation %s on tunnel %s/%s", AnnotationDisabled, target.namespace, target.name)
+	default:
+		return ""
+	}
+}
+
+// tunnelSwitched passes PangolinTunnel updates that change the disabled
+// annotation; other tunnel changes do not affect Ingresses.
+var tunnelSwitched = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return isDisabled(e.ObjectOld) != isDisabled(e.ObjectNew)
+	},
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// LabelCluster records the cluster of the Ingress a PangolinResource was
+// created for in multi-cluster mode. Owner references cannot point across
+// clusters, so such resources are controlled through their LabelIngressUID
+// label instead.
+const LabelCluster = "pic.ingress.k8s.io/cluster"
+
+// ManagementCluster is the cluster running pangolin-operator in
+// multi-cluster mode. PIC watches Ingresses in the local cluster and reads
+// PangolinTunnels from and writes PangolinResources to the management
+// cluster.
+type ManagementCluster struct {
+	cluster.Cluster
+
+	// ClusterName identifies the local cluster in the names, labels and
+	// display names of the PangolinResources.
+	ClusterName string
+}
+
+// NewManagementCluster connects to the management cluster named by the
+// managementKubeconfig setting. It returns nil without one. The cluster's
+// cache is started by SetupWithManager.
+func NewManagementCluster(cfg *config.Config, scheme *runtime.Scheme) (*ManagementCluster, error) {
+	if cfg.ManagementKubeconfig == "" {
+		return nil, nil
+	}
+	restConfig, err := clientcmd.BuildConfigFromFlags("", cfg.ManagementKubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load management cluster kubeconfig: %w", err)
+	}
+	c, err := cluster.New(restConfig, func(o *cluster.Options) { o.Scheme = scheme })
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to management cluster: %w", err)
+	}
+	return &ManagementCluster{Cluster: c, ClusterName: cfg.ClusterName}, nil
+}
+
+// resources returns the client for PangolinResources and PangolinTunnels:
+// the management cluster's in multi-cluster mode, the local one otherwise.
+func (r *IngressReconciler) resources() client.Client {
+	if r.Management != nil {
+		return r.Management.GetClient()
+	}
+	return r.Client
+}
+
+// clusterName returns the name of the local cluster in multi-cluster mode,
+// and "" otherwise.
+func (r *IngressReconciler) clusterName() string {
+	if r.Management != nil {
+		return r.Management.ClusterName
+	}
+	return ""
+}
+
+// resourceSelector selects the PangolinResources PIC created for this
+// cluster's Ingresses. The management cluster also holds those of the other
+// clusters, told apart by their LabelCluster label.
+func (r *IngressReconciler) resourceSelector() []client.ListOption {
+	opts := []client.ListOption{client.HasLabels{LabelIngressUID}}
+	if name := r.clusterName(); name != "" {
+		opts = append(opts, client.MatchingLabels{LabelCluster: name})
+	}
+	return opts
+}
+
+// missingNamespace returns the namespace the management cluster reported
+// missing when writing a PangolinResource failed for lack of it.
+// PangolinResources are written to the namespace of their Ingress, which is
+// not created there.
+func (r *IngressReconciler) missingNamespace(err error) (string, bool) {
+	var status apierrors.APIStatus
+	if r.Management == nil || !apierrors.IsNotFound(err) || !errors.As(err, &status) {
+		return "", false
+	}
+	details := status.Status().Details
+	if details == nil || details.Kind != "namespaces" {
+		return "", false
+	}
+	return details.Name, true
+}
+
+// setController makes the Ingress the controller owner of desired. In
+// multi-cluster mode the LabelCluster and LabelIngressUID labels stand in for
+// the owner reference, and the cleanup finalizer for garbage collection.
+func (r *IngressReconciler) setController(ingress *networkingv1.Ingress, desired *pangolincrd.PangolinResource) error {
+	if r.Management != nil {
+		return nil
+	}
+	return ctrl.SetControllerReference(ingress, desired, r.Scheme)
+}
+
+// controllerUID returns the UID of the Ingress controlling the resource:
+// that of its controller owner reference or, for a resource created in
+// multi-cluster mode, its LabelIngressUID label.
+func controllerUID(obj metav1.Object) (types.UID, bool) {
+	if ref := metav1.GetControllerOf(obj); ref != nil {
+		return ref.UID, true
+	}
+	labels := obj.GetLabels()
+	if labels[LabelCluster] != "" && labels[LabelIngressUID] != "" {
+		return types.UID(labels[LabelIngressUID]), true
+	}
+	return "", false
+}
+
+// ingressForResource maps a PangolinResource in the management cluster to
+// the Ingress of this cluster it was created for.
+func (r *IngressReconciler) ingressForResource(_ context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels[LabelCluster] != r.clusterName() || labels[LabelIngressName] == "" {
+		return nil
+	}
+	namespace := labels[LabelIngressNamespace]
+	if namespace == "" {
+		namespace = obj.GetNamespace()
+	}
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Namespace: namespace, Name: labels[LabelIngressName]},
+	}}
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/metrics"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// SweepOrphans checks every PangolinResource labeled by PIC against the
+// Ingresses in the cluster. Resources whose Ingress still exists but lost
+// the owner reference, e.g. after a backup restore, get it back. Resources
+// whose Ingress is gone are deleted, or with the adopt orphan policy handed
+// to an Ingress of the same name recreated in their namespace. A resource
+// that fails does not stop the sweep; the errors are returned together.
+func (r *IngressReconciler) SweepOrphans(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "sweepOrphans")
+	defer span.End()
+
+	var resources pangolincrd.PangolinResourceList
+	if err := r.resources().List(ctx, &resources, client.HasLabels{LabelIngressUID}); err != nil {
+		err = fmt.Errorf("failed to list PangolinResources: %w", err)
+		recordError(span, err)
+		return err
+	}
+	resources.Items = r.ownResources(resources.Items)
+	if len(resources.Items) == 0 {
+		return nil
+	}
+
+	var ingresses networkingv1.IngressList
+	if err := r.List(ctx, &ingresses); err != nil {
+		err = fmt.Errorf("failed to list Ingresses: %w", err)
+		recordError(span, err)
+		return err
+	}
+	byUID := make(map[types.UID]*networkingv1.Ingress, len(ingresses.Items))
+	byName := make(map[types.NamespacedName]*networkingv1.Ingress, len(ingresses.Items))
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		byUID[ingress.UID] = ingress
+		byName[types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}] = ingress
+	}
+
+	adopt := r.config().OrphanPolicy == config.OrphanPolicyAdopt
+	var errs []error
+	for i := range resources.Items {
+		resource := &resources.Items[i]
+
+		owner := byUID[types.UID(resource.Labels[LabelIngressUID])]
+		if owner != nil && owner.Namespace != resource.Namespace {
+			owner = nil
+		}
+		if owner != nil && isControlledBy(resource, owner) {
+			continue
+		}
+		if owner == nil && adopt {
+			owner = byName[types.NamespacedName{Namespace: resource.Namespace, Name: resource.Labels[LabelIngressName]}]
+		}
+
+		var err error
+		if owner != nil {
+			if err = r.adoptResource(ctx, resource, owner); err == nil {
+				metrics.OrphansSweptTotal.WithLabelValues(metrics.SweepAdopt).Inc()
+			}
+		} else {
+			err = r.deleteOrphan(ctx, resource)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		recordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// isControlledBy reports whether the Ingress is the controller owner of the resource.
+func isControlledBy(resource *pangolincrd.PangolinResource, ingress *networkingv1.Ingress) bool {
+	uid, controlled := controllerUID(resource)
+	return controlled && uid == ingress.UID
+}
+
+// adoptResource makes the Ingress the owner of the resource, replacing the
+// owner label and any owner reference to another Ingress. The next reconcile
+// of the Ingress keeps or deletes the resource like any other it owns.
+func (r *IngressReconciler) adoptResource(
+	ctx context.Context,
+	resource *pangolincrd.PangolinResource,
+	ingress *networkingv1.Ingress,
+) error {
+	log := r.Log.WithValues("ingress", types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace})
+
+	base := resource.DeepCopy()
+	refs := resource.OwnerReferences[:0]
+	for _, ref := range resource.OwnerReferences {
+		if ref.Kind != "Ingress" {
+			refs = append(refs, ref)
+		}
+	}
+	resource.OwnerReferences = refs
+	resource.Labels[LabelIngressUID] = string(ingress.UID)
+	if cluster := r.clusterName(); cluster != "" {
+		resource.Labels[LabelCluster] = cluster
+	} else if err := controllerutil.SetControllerReference(ingress, resource, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set owner reference on %s/%s: %w", resource.Namespace, resource.Name, err)
+	}
+	if err := r.resources().Patch(ctx, resource, client.MergeFrom(base)); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to adopt PangolinResource %s/%s: %w", resource.Namespace, resource.Name, err)
+	}
+
+	log.Info("Adopted PangolinResource", "resource", resource.Name)
+	r.Recorder.Event(ingress, corev1.EventTypeNormal, "Adopted",
+		fmt.Sprintf("Adopted PangolinResource %s", resource.Name))
+	return nil
+}
+
+// deleteOrphan deletes a resource whose Ingress no longer exists.
+func (r *IngressReconciler) deleteOrphan(ctx context.Context, resource *pangolincrd.PangolinResource) error {
+	if err := r.resources().Delete(ctx, resource); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete orphaned PangolinResource %s/%s: %w", resource.Namespace, resource.Name, err)
+	}
+
+	r.Log.Info("Deleted PangolinResource without Ingress",
+		"resource", types.NamespacedName{Namespace: resource.Namespace, Name: resource.Name},
+		"ingress", resource.Labels[LabelIngressName])
+	metrics.OrphansSweptTotal.WithLabelValues(metrics.SweepDelete).Inc()
+	metrics.ResourceOperationsTotal.WithLabelValues(metrics.OperationDelete).Inc()
+	metrics.StopReadyTimer(resource.Namespace + "/" + resource.Name)
+	return nil
+}
+
+// sweepOrphansEvery runs SweepOrphans every interval until ctx is done.
+// Failures are logged and retried on the next tick.
+func (r *IngressReconciler) sweepOrphansEvery(interval time.Duration) func(context.Context) error {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				if err := r.SweepOrphans(ctx); err != nil {
+					r.Log.Error(err, "Orphan sweep failed")
+				}
+			}
+		}
+	}
+}
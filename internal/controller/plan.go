@@ -0,0 +1,518 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// planAction is what Reconcile does with an Ingress.
+type planAction int
+
+const (
+	// actionApply applies the PangolinResource of every host and deletes
+	// the resources no host needs any more.
+	actionApply planAction = iota
+
+	// actionIgnore leaves an Ingress outside the shard to another instance,
+	// withdrawing the resources this instance labeled as its own.
+	actionIgnore
+
+	// actionFinalize waits for the PangolinResources of a deleted Ingress.
+	actionFinalize
+
+	// actionPause leaves the PangolinResources of a paused Ingress unchanged.
+	actionPause
+
+	// actionUnmanage deletes the PangolinResources of an Ingress PIC does
+	// not manage and releases its finalizer.
+	actionUnmanage
+
+	// actionWithdraw deletes every PangolinResource of an Ingress that may
+	// no longer be exposed.
+	actionWithdraw
+
+	// actionRetry requeues an Ingress whose tunnel is not found.
+	actionRetry
+
+	// actionFail fails the reconcile so it is retried with backoff.
+	actionFail
+)
+
+// plannedEvent is an event recorded on the Ingress when the plan is carried out.
+type plannedEvent struct {
+	eventType string
+	reason    string
+	message   string
+
+	// state, if set, records the event only when it differs from the state
+	// last reported for the Ingress and reason; see stateReasons.
+	state string
+}
+
+// stateReasons are the reasons of events that report a lasting state of the
+// Ingress. They are recorded on transitions rather than on every reconcile.
+var stateReasons = []string{"Paused", "Scheduled", "Expired"}
+
+// ingressPlan is what PIC decides to do with an Ingress. It is computed from
+// the cluster without changing it: Reconcile carries it out and Explain
+// prints it, so both always agree.
+type ingressPlan struct {
+	action planAction
+
+	// reason explains actions other than actionApply; actionWithdraw
+	// reports it in the Deleted events.
+	reason string
+
+	// err fails the reconcile with actionFail.
+	err error
+
+	// events are recorded on the Ingress before the action is carried out.
+	events []plannedEvent
+
+	// ingress is the Ingress with the namespace defaults applied.
+	ingress *networkingv1.Ingress
+
+	// shard explains why the Ingress belongs to another instance's shard.
+	shard string
+
+	managed       bool
+	managedReason string
+	class         *IngressClassSettings
+	classErr      error
+
+	// defaults lists the namespace defaults applied, as
+	// "annotation=value (source)".
+	defaults []string
+
+	tunnelRef    string
+	tunnelSource string
+	tunnelErr    error
+	target       tunnelTarget
+	policy       *namespacePolicy
+
+	exposure
+
+	// hosts are the Ingress's unique hosts and what is applied for each.
+	hosts []hostPlan
+
+	// orphans are the resources the Ingress controls that no host needs;
+	// they are deleted by actionApply, actionUnmanage and actionWithdraw.
+	orphans []*pangolincrd.PangolinResource
+
+	// violations are the live resources the Ingress controls that break a
+	// cluster policy. The actions that leave the resources unchanged
+	// (actionPause, actionRetry and actionFail) still delete them.
+	violations []*pangolincrd.PangolinResource
+}
+
+// hostPlan is what is applied for a single host.
+type hostPlan struct {
+	RenderedHost
+
+	// adopt reports that Resource takes over an uncontrolled resource
+	// carrying AnnotationAdopt.
+	adopt bool
+
+	// collision is the resource created for another host under the
+	// generated name, which moved Resource to the fallback name.
+	collision *pangolincrd.PangolinResource
+
+	// live is the resource under Resource's name, nil if it does not exist.
+	live *pangolincrd.PangolinResource
+
+	// predecessors are the resources Resource replaces once it is Ready.
+	predecessors []*pangolincrd.PangolinResource
+
+	// renameErr explains why the predecessors are kept for good: Resource
+	// failed or did not become Ready in time.
+	renameErr error
+}
+
+// event records an event for the Ingress.
+func (p *ingressPlan) event(eventType, reason, message string) {
+	p.events = append(p.events, plannedEvent{eventType: eventType, reason: reason, message: message})
+}
+
+// stateEvent plans an event that is recorded only when state changes.
+func (p *ingressPlan) stateEvent(eventType, reason, state, message string) {
+	p.events = append(p.events, plannedEvent{eventType: eventType, reason: reason, message: message, state: state})
+}
+
+// describe summarizes the action for pic explain.
+func (p *ingressPlan) describe() string {
+	action := p.describeAction()
+	if len(p.violations) > 0 {
+		names := resourceNames(p.violations)
+		sort.Strings(names)
+		action += "; delete " + strings.Join(names, ", ") + ", breaking a cluster policy"
+	}
+	return action
+}
+
+// describeAction summarizes the action without the policy violations.
+func (p *ingressPlan) describeAction() string {
+	switch p.action {
+	case actionIgnore:
+		if len(p.orphans) > 0 {
+			return "delete this instance's PangolinResources, left to another PIC instance"
+		}
+		return "none, left to another PIC instance"
+	case actionFinalize:
+		return "finalize, the Ingress is being deleted"
+	case actionPause:
+		return "none, the Ingress is paused"
+	case actionUnmanage:
+		return "delete every PangolinResource, the Ingress is not managed"
+	case actionWithdraw:
+		return "delete every PangolinResource: " + p.reason
+	case actionRetry:
+		return "retry: " + p.reason
+	case actionFail:
+		return fmt.Sprintf("fail: %v", p.err)
+	}
+	if len(p.hosts) == 0 {
+		return "none, the Ingress has no hosts"
+	}
+	return "apply the PangolinResources of the hosts below"
+}
+
+// plan decides what Reconcile does with the Ingress. It only reads from the
+// cluster; an error means the cluster could not be read.
+func (r *IngressReconciler) plan(ctx context.Context, ingress *networkingv1.Ingress) (*ingressPlan, error) {
+	p := &ingressPlan{ingress: ingress}
+
+	// A deleted Ingress only waits for its PangolinResources to go, in
+	// whatever shard: an Ingress that left every shard still holds the
+	// cleanup finalizer
+	if !ingress.DeletionTimestamp.IsZero() {
+		p.action = actionFinalize
+		return p, nil
+	}
+
+	// The resources the Ingress controls are looked up once
+	owned, err := r.ownedResources(ctx, ingress)
+	if err != nil {
+		return nil, err
+	}
+
+	// An Ingress outside this instance's shard belongs to another instance,
+	// which takes over once this instance's resources are withdrawn
+	if p.shard = r.outsideShard(ingress); p.shard != "" {
+		p.action, p.reason, p.orphans = actionIgnore, p.shard, r.leftShard(owned)
+		return p, nil
+	}
+
+	// Cluster policies hold whatever the Ingress's annotations say: the live
+	// resources are checked before pausing or failing can leave them alone
+	policy, err := r.resolvePolicy(ctx, ingress.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	var violations []*pangolincrd.PangolinResource
+	var violationEvents []plannedEvent
+	for _, resource := range owned {
+		if err := policy.check(resource); err != nil {
+			violations = append(violations, resource)
+			violationEvents = append(violationEvents, plannedEvent{
+				eventType: corev1.EventTypeWarning,
+				reason:    "PolicyViolation",
+				message:   fmt.Sprintf("PangolinResource %s: %s", resource.Name, err.Error()),
+			})
+		}
+	}
+	leaveUnchanged := func(action planAction) {
+		p.action, p.violations = action, violations
+		p.events = append(p.events, violationEvents...)
+	}
+
+	// Read the IngressClass and its PIC parameters
+	p.class, p.classErr = r.resolveIngressClass(ctx, ingress)
+	if p.classErr != nil {
+		leaveUnchanged(actionFail)
+		p.err = p.classErr
+		p.managedReason = "IngressClass could not be resolved"
+		p.event(corev1.EventTypeWarning, "IngressClassInvalid", p.classErr.Error())
+		return p, nil
+	}
+
+	// If we previously managed it, delete the PangolinResources
+	p.managed, p.managedReason = r.managedReason(ingress, p.class)
+	if !p.managed {
+		p.action, p.orphans = actionUnmanage, owned
+		return p, nil
+	}
+
+	// A paused Ingress is left alone, except for policy violations
+	if isPaused(ingress) {
+		leaveUnchanged(actionPause)
+		p.stateEvent(corev1.EventTypeNormal, "Paused", "paused",
+			fmt.Sprintf("Annotation %s is set, PangolinResources are left unchanged", AnnotationPaused))
+		return p, nil
+	}
+
+	// Merge namespace defaults beneath the Ingress annotations; the default
+	// tunnel only replaces the controller's
+	defaults, err := r.resolveNamespaceDefaults(ctx, ingress.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range defaults.missing(ingress) {
+		p.defaults = append(p.defaults,
+			fmt.Sprintf("%s=%s (%s)", key, defaults.Annotations[key], defaults.Sources[key]))
+	}
+	p.ingress = defaults.Apply(ingress)
+	p.policy = policy
+
+	// Resolve the tunnel reference
+	p.tunnelRef, p.tunnelSource, p.tunnelErr = r.resolveTunnelSource(p.ingress, p.class, defaults)
+	if p.tunnelErr != nil {
+		leaveUnchanged(actionFail)
+		p.err = p.tunnelErr
+		p.event(corev1.EventTypeWarning, "TunnelResolutionFailed", p.tunnelErr.Error())
+		return p, nil
+	}
+	if tunnelName, source := defaults.tunnel(); source != "" && p.tunnelSource == source {
+		p.defaults = append(p.defaults, fmt.Sprintf("%s=%s (%s)", AnnotationTunnelName, tunnelName,
+			defaults.Sources[AnnotationTunnelName]))
+	}
+
+	// Validate tunnel exists, may be referenced from this namespace, and get its namespace
+	p.target, p.tunnelErr = r.validateTunnel(ctx, ingress.Namespace, p.tunnelRef)
+	if errors.Is(p.tunnelErr, errTunnelNotGranted) {
+		// A PangolinTunnelGrant change requeues the Ingress
+		p.action, p.reason, p.orphans = actionWithdraw, "tunnel not permitted", owned
+		p.event(corev1.EventTypeWarning, "TunnelNotPermitted", p.tunnelErr.Error())
+		return p, nil
+	}
+	if p.tunnelErr != nil {
+		leaveUnchanged(actionRetry)
+		p.reason = fmt.Sprintf("tunnel %q not found", p.tunnelRef)
+		p.event(corev1.EventTypeWarning, "TunnelNotFound", fmt.Sprintf("Tunnel %q not found", p.tunnelRef))
+		return p, nil
+	}
+	if p.shard = r.tunnelOutsideShard(p.target); p.shard != "" {
+		p.action, p.reason, p.orphans = actionIgnore, p.shard, r.leftShard(owned)
+		return p, nil
+	}
+	if len(p.target.ambiguous) > 0 {
+		p.event(corev1.EventTypeWarning, "AmbiguousTunnel",
+			fmt.Sprintf("Tunnel %q also exists in %s; using %s/%s, set tunnelPreference or reference it as namespace/name",
+				p.target.name, strings.Join(p.target.ambiguous, ", "), p.target.namespace, p.target.name))
+	}
+
+	// Enforce cluster policies on the tunnel; a disallowed tunnel withdraws
+	// everything the Ingress exposes
+	if err := p.policy.checkTunnel(p.target.namespace, p.target.name); err != nil {
+		p.tunnelErr = err
+		p.action, p.reason, p.orphans = actionWithdraw, "policy violation", owned
+		p.event(corev1.EventTypeWarning, "PolicyViolation", err.Error())
+		return p, nil
+	}
+
+	// An expired Ingress is disabled, or withdrawn with the delete expiry
+	// action; outside its scheduled window it is disabled
+	p.exposure = evaluateExposure(ingress, r.now())
+	switch {
+	case p.expiryErr != nil:
+		p.event(corev1.EventTypeWarning, "InvalidExpiry", p.expiryErr.Error())
+	case p.expired:
+		expiry := p.expiry.Format(time.RFC3339)
+		p.stateEvent(corev1.EventTypeNormal, "Expired", expiry, "Ingress expired at "+expiry)
+		if r.config().ExpiryAction == config.ExpiryActionDelete {
+			p.action, p.reason, p.orphans = actionWithdraw, "expired", undeleted(owned)
+			return p, nil
+		}
+	case p.window.err != nil:
+		p.event(corev1.EventTypeWarning, "InvalidSchedule", p.window.err.Error())
+	case p.window.scheduled:
+		p.stateEvent(corev1.EventTypeNormal, "Scheduled", p.window.describe(), "Ingress is "+p.window.describe())
+	}
+
+	if err := r.planHosts(ctx, p, owned); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// undeleted returns the resources that are not already being deleted.
+func undeleted(resources []*pangolincrd.PangolinResource) []*pangolincrd.PangolinResource {
+	var found []*pangolincrd.PangolinResource
+	for _, resource := range resources {
+		if resource.DeletionTimestamp.IsZero() {
+			found = append(found, resource)
+		}
+	}
+	return found
+}
+
+// ownedResources returns the resources the Ingress controls, through
+// OwnerUIDIndex, leaving out those of other instances and clusters.
+func (r *IngressReconciler) ownedResources(ctx context.Context, ingress *networkingv1.Ingress) ([]*pangolincrd.PangolinResource, error) {
+	var list pangolincrd.PangolinResourceList
+	if err := r.resources().List(ctx, &list,
+		client.InNamespace(ingress.Namespace),
+		client.MatchingFields{OwnerUIDIndex: string(ingress.UID)},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list PangolinResources: %w", err)
+	}
+	own := r.ownResources(list.Items)
+	owned := make([]*pangolincrd.PangolinResource, 0, len(own))
+	for i := range own {
+		owned = append(owned, &own[i])
+	}
+	return owned, nil
+}
+
+// getResource returns the PangolinResource namespace/name, or nil if it
+// does not exist.
+func (r *IngressReconciler) getResource(ctx context.Context, namespace, name string) (*pangolincrd.PangolinResource, error) {
+	var resource pangolincrd.PangolinResource
+	if err := r.resources().Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &resource); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get PangolinResource %s/%s: %w", namespace, name, err)
+	}
+	return &resource, nil
+}
+
+// planHosts decides the PangolinResource of each host: its desired state,
+// the live resource it is applied to and the resources it replaces. The
+// resources the Ingress controls that no host needs are its orphans. An
+// Ingress without hosts leaves its resources alone. The namespace's other
+// resources are only listed for a host without a resource, which may adopt
+// one.
+func (r *IngressReconciler) planHosts(ctx context.Context, p *ingressPlan, owned []*pangolincrd.PangolinResource) error {
+	ingress := p.ingress
+	if len(ingress.Spec.Rules) == 0 {
+		p.event(corev1.EventTypeWarning, "NoRules", "Ingress has no rules defined")
+		return nil
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host == "" {
+			p.event(corev1.EventTypeWarning, "EmptyHost", "Rule with empty host skipped")
+		}
+	}
+	groups := collectHostPaths(ingress)
+	if len(groups) == 0 {
+		return nil
+	}
+
+	var adoptable []pangolincrd.PangolinResource
+	listed := false
+	desiredNames := make(map[string]bool)
+	for _, rendered := range r.renderHosts(ingress, p.class, groups, p.target, p.policy, p.window) {
+		host := hostPlan{RenderedHost: rendered}
+		if rendered.Err != nil {
+			// Hosts rejected by a cluster policy are not exposed; an existing
+			// resource for the host is an orphan
+			reason := "InvalidHost"
+			if errors.Is(rendered.Err, errPolicyViolation) {
+				reason = "PolicyViolation"
+			}
+			p.event(corev1.EventTypeWarning, reason, fmt.Sprintf("Host %q: %s", rendered.Host, rendered.Err.Error()))
+			p.hosts = append(p.hosts, host)
+			continue
+		}
+		desired := rendered.Resource
+
+		// Apply to the resource under the generated name or, without one of
+		// this instance's, to a resource already serving the host: one the
+		// Ingress adopted before, or one opted in for adoption
+		live, err := r.getResource(ctx, desired.Namespace, desired.Name)
+		if err != nil {
+			return err
+		}
+		if live == nil || r.isForeign(live) {
+			match, adopt := matchExisting(ingress, desired, derefResources(owned))
+			if match == nil {
+				if !listed {
+					var all pangolincrd.PangolinResourceList
+					if err := r.resources().List(ctx, &all, client.InNamespace(ingress.Namespace)); err != nil {
+						return fmt.Errorf("failed to list PangolinResources: %w", err)
+					}
+					adoptable, listed = r.ownResources(all.Items), true
+				}
+				match, adopt = matchExisting(ingress, desired, adoptable)
+			}
+			if match != nil {
+				desired.Name, host.adopt, live = match.Name, adopt, match
+			}
+		}
+		host.live = live
+
+		// A resource under the name that was created for another host means
+		// the generated names collide; this host moves to the longer hash
+		if host.live != nil {
+			if fallback := collisionFallback(ingress, desired, host.live); fallback != "" {
+				host.collision = host.live
+				desired.Name = fallback
+				if host.live, err = r.getResource(ctx, desired.Namespace, desired.Name); err != nil {
+					return err
+				}
+				if host.live != nil && collisionFallback(ingress, desired, host.live) != "" {
+					host.Err = fmt.Errorf("PangolinResource %s was also created for host %q", desired.Name,
+						host.live.Annotations[AnnotationHost])
+					p.event(corev1.EventTypeWarning, "NameCollision", fmt.Sprintf("Host %q: %s", rendered.Host, host.Err.Error()))
+					p.hosts = append(p.hosts, host)
+					continue
+				}
+				p.event(corev1.EventTypeWarning, "NameCollision",
+					fmt.Sprintf("PangolinResource %s was created for host %q; using %s for host %q",
+						host.collision.Name, host.collision.Annotations[AnnotationHost], desired.Name, rendered.Host))
+			}
+		}
+		if host.adopt {
+			p.event(corev1.EventTypeNormal, "Adopted",
+				fmt.Sprintf("Adopted PangolinResource %s for host %q", desired.Name, rendered.Host))
+		}
+		desiredNames[desired.Name] = true
+
+		// Resources for the host under an old name keep serving it until the
+		// renamed one is Ready (make-before-break),
+		// unless they break a cluster policy
+		for _, old := range predecessors(ingress, desired, rendered.Host, owned) {
+			if p.policy.check(old) == nil {
+				host.predecessors = append(host.predecessors, old)
+				desiredNames[old.Name] = true
+			}
+		}
+		if len(host.predecessors) > 0 {
+			if host.renameErr = r.renameStall(host.live); host.renameErr != nil {
+				p.event(corev1.EventTypeWarning, "RenameStalled",
+					fmt.Sprintf("Host %q: %s; %s keeps serving it", rendered.Host, host.renameErr.Error(),
+						strings.Join(resourceNames(host.predecessors), ", ")))
+			}
+		}
+		p.hosts = append(p.hosts, host)
+	}
+
+	for _, resource := range owned {
+		if !desiredNames[resource.Name] {
+			p.orphans = append(p.orphans, resource)
+		}
+	}
+	return nil
+}
+
+// derefResources copies the resources into a slice of values.
+func derefResources(resources []*pangolincrd.PangolinResource) []pangolincrd.PangolinResource {
+	values := make([]pangolincrd.PangolinResource, 0, len(resources))
+	for _, resource := range resources {
+		values = append(values, *resource)
+	}
+	return values
+}
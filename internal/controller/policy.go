@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
+)
+
+// errPolicyViolation is returned when a PangolinClusterPolicy rule rejects a
+// host or a tunnel.
+var errPolicyViolation = errors.New("policy violation")
+
+// namespacePolicy holds the PangolinClusterPolicy rules that apply to the
+// Ingresses of one namespace. A nil *namespacePolicy allows everything.
+type namespacePolicy struct {
+	namespace   string
+	labels      labels.Set
+	rules       []policyNamespaceRule
+	tunnelRules []policyTunnelRule
+}
+
+// policyNamespaceRule is a NamespaceRule selecting the namespace.
+type policyNamespaceRule struct {
+	policy string
+	rule   piccrd.NamespaceRule
+}
+
+// policyTunnelRule is a TunnelRule with its parsed selector.
+type policyTunnelRule struct {
+	policy   string
+	tunnel   string
+	selector labels.Selector
+}
+
+// resolvePolicy collects the cluster policy rules for a namespace. An
+// invalid selector fails the reconcile rather than silently allowing exposure.
+func (r *IngressReconciler) resolvePolicy(ctx context.Context, namespace string) (*namespacePolicy, error) {
+	var policies piccrd.PangolinClusterPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return nil, fmt.Errorf("failed to list PangolinClusterPolicies: %w", err)
+	}
+	if len(policies.Items) == 0 {
+		return nil, nil
+	}
+
+	policy := &namespacePolicy{namespace: namespace}
+
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get Namespace %s: %w", namespace, err)
+		}
+	}
+	policy.labels = labels.Set(ns.Labels)
+
+	for _, item := range policies.Items {
+		for _, rule := range item.Spec.NamespaceRules {
+			selector, err := metav1.LabelSelectorAsSelector(&rule.NamespaceSelector)
+			if err != nil {
+				return nil, fmt.Errorf("PangolinClusterPolicy %s rule %q: invalid namespaceSelector: %w",
+					item.Name, rule.Name, err)
+			}
+			if selector.Matches(policy.labels) {
+				policy.rules = append(policy.rules, policyNamespaceRule{policy: item.Name, rule: rule})
+			}
+		}
+		for _, rule := range item.Spec.TunnelRules {
+			selector, err := metav1.LabelSelectorAsSelector(&rule.NamespaceSelector)
+			if err != nil {
+				return nil, fmt.Errorf("PangolinClusterPolicy %s tunnel rule %q: invalid namespaceSelector: %w",
+					item.Name, rule.Tunnel, err)
+			}
+			policy.tunnelRules = append(policy.tunnelRules,
+				policyTunnelRule{policy: item.Name, tunnel: rule.Tunnel, selector: selector})
+		}
+	}
+
+	return policy, nil
+}
+
+// checkTunnel returns an error if tunnel rules restrict the tunnel and none
+// of them selects the namespace. Rules match the tunnel by name or by
+// namespace/name.
+func (p *namespacePolicy) checkTunnel(tunnelNamespace, tunnelName string) error {
+	if p == nil {
+		return nil
+	}
+
+	var restrictedBy []string
+	for _, rule := range p.tunnelRules {
+		if rule.tunnel != tunnelName && rule.tunnel != tunnelNamespace+"/"+tunnelName {
+			continue
+		}
+		if rule.selector.Matches(p.labels) {
+			return nil
+		}
+		restrictedBy = append(restrictedBy, rule.policy)
+	}
+	if len(restrictedBy) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: tunnel %q may not be used from namespace %s (PangolinClusterPolicy %s)",
+		errPolicyViolation, tunnelName, p.namespace, strings.Join(restrictedBy, ", "))
+}
+
+// checkResource returns an error describing every namespace rule the desired
+// PangolinResource breaks.
+func (p *namespacePolicy) checkResource(resource *pangolincrd.PangolinResource) error {
+	if p == nil || resource.Spec.HTTPConfig == nil {
+		return nil
+	}
+
+	cfg := resource.Spec.HTTPConfig
+	fqdn := cfg.DomainName
+	if cfg.Subdomain != "" {
+		fqdn = cfg.Subdomain + "." + cfg.DomainName
+	}
+
+	var violations []string
+	for _, entry := range p.rules {
+		rule := entry.rule
+		var broken []string
+		if rule.RequireSSO && !cfg.SSO {
+			broken = append(broken, "SSO is required")
+		}
+		if rule.RequireBlockAccess && !cfg.BlockAccess {
+			broken = append(broken, "block-access is required")
+		}
+		if !domainAllowed(fqdn, rule.AllowedDomains) {
+			broken = append(broken, fmt.Sprintf("domain %q is not in %s", fqdn, strings.Join(rule.AllowedDomains, ", ")))
+		}
+		if len(broken) > 0 {
+			violations = append(violations, fmt.Sprintf("%s (PangolinClusterPolicy %s rule %q)",
+				strings.Join(broken, ", "), entry.policy, rule.Name))
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", errPolicyViolation, strings.Join(violations, "; "))
+}
+
+// check returns an error if the live PangolinResource uses a tunnel the
+// namespace may not use or breaks a namespace rule. It only looks at the
+// resource, never at the annotations of its Ingress.
+func (p *namespacePolicy) check(resource *pangolincrd.PangolinResource) error {
+	tunnelNamespace := resource.Spec.TunnelRef.Namespace
+	if tunnelNamespace == "" {
+		tunnelNamespace = resource.Namespace
+	}
+	if err := p.checkTunnel(tunnelNamespace, resource.Spec.TunnelRef.Name); err != nil {
+		return err
+	}
+	return p.checkResource(resource)
+}
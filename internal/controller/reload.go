@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+)
+
+// config returns the active configuration.
+func (r *IngressReconciler) config() *config.Config {
+	r.configMu.RLock()
+	defer r.configMu.RUnlock()
+	return r.Config
+}
+
+// UpdateConfig replaces the active configuration, typically from a
+// config.Watcher listener. When the tunnel mapping, default tunnel or backend
+// scheme changed, every Ingress is requeued so PangolinResources follow the
+// new settings without waiting for the next resync.
+//
+//	watcher.OnChange(func(_, next *config.Config) { reconciler.UpdateConfig(next) })
+func (r *IngressReconciler) UpdateConfig(cfg *config.Config) {
+	r.configMu.Lock()
+	previous := r.Config
+	r.Config = cfg
+	r.configMu.Unlock()
+
+	if previous != nil && config.TunnelSettingsEqual(previous, cfg) {
+		return
+	}
+
+	r.Log.Info("Tunnel settings changed, requeueing all Ingresses")
+	r.RequeueAll()
+}
+
+// RequeueAll schedules a reconcile of every Ingress. It never blocks; if a
+// requeue is already pending the call is a no-op.
+func (r *IngressReconciler) RequeueAll() {
+	if r.requeueAll == nil {
+		return
+	}
+	select {
+	case r.requeueAll <- event.GenericEvent{Object: &networkingv1.Ingress{}}:
+	default:
+	}
+}
+
+// allIngresses maps a requeue trigger to a request for every Ingress.
+func (r *IngressReconciler) allIngresses(ctx context.Context, _ client.Object) []reconcile.Request {
+	var ingresses networkingv1.IngressList
+	if err := r.List(ctx, &ingresses); err != nil {
+		r.Log.Error(err, "Failed to list Ingresses for requeue")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(ingresses.Items))
+	for _, ingress := range ingresses.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/metrics"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+const (
+	// AnnotationHost records the Ingress host a PangolinResource was created
+	// for. It identifies the resource a renamed one replaces.
+	AnnotationHost = "pic.ingress.k8s.io/host"
+
+	// renamePollInterval is how often a renamed PangolinResource is rechecked
+	// while it is not yet Ready.
+	renamePollInterval = 15 * time.Second
+
+	// renameTimeout is how long a renamed PangolinResource may take to
+	// become Ready before PIC stops waiting for it.
+	renameTimeout = 10 * time.Minute
+)
+
+// predecessors returns the resources the Ingress controls for host under
+// another name than desired, left behind when the Ingress was renamed or the
+// naming scheme changed. Adopted resources keep their name and are matched
+// by matchExisting instead.
+func predecessors(
+	ingress *networkingv1.Ingress,
+	desired *pangolincrd.PangolinResource,
+	host string,
+	owned []*pangolincrd.PangolinResource,
+) []*pangolincrd.PangolinResource {
+	var found []*pangolincrd.PangolinResource
+	for _, resource := range owned {
+		if resource.Namespace != desired.Namespace || resource.Name == desired.Name ||
+			!resource.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if resource.Annotations[AnnotationHost] != host || resource.Annotations[AnnotationAdopt] == "true" {
+			continue
+		}
+		if isControlledBy(resource, ingress) {
+			found = append(found, resource)
+		}
+	}
+	return found
+}
+
+// renameStall returns why the rename to the resource under the new name
+// stalled: pangolin-operator reports it Failed, or it is not Ready
+// renameTimeout after its creation. The resources it replaces keep serving
+// the host and polling stops; a status change of the new resource requeues
+// the Ingress. It returns nil while the rename can still complete.
+func (r *IngressReconciler) renameStall(renamed *pangolincrd.PangolinResource) error {
+	if renamed == nil {
+		return nil
+	}
+	switch {
+	case renamed.Status.Phase == pangolincrd.PhaseReady:
+		return nil
+	case renamed.Status.Phase == pangolincrd.PhaseFailed:
+		if ready := meta.FindStatusCondition(renamed.Status.Conditions, "Ready"); ready != nil && ready.Message != "" {
+			return fmt.Errorf("PangolinResource %s failed: %s", renamed.Name, ready.Message)
+		}
+		return fmt.Errorf("PangolinResource %s failed", renamed.Name)
+	case !renamed.CreationTimestamp.IsZero() && r.now().Sub(renamed.CreationTimestamp.Time) > renameTimeout:
+		return fmt.Errorf("PangolinResource %s is not Ready %s after its creation", renamed.Name, renameTimeout)
+	}
+	return nil
+}
+
+// replacePredecessors deletes the resources a renamed PangolinResource
+// replaces once it is Ready, so the host is served throughout the rename.
+// It reports whether the rename is still waiting for the new resource.
+func (r *IngressReconciler) replacePredecessors(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	renamed *pangolincrd.PangolinResource,
+	olds []*pangolincrd.PangolinResource,
+) (bool, error) {
+	log := r.Log.WithValues(
+		"ingress", types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace},
+		"pangolinresource", renamed.Name,
+	)
+
+	if renamed.Status.Phase != pangolincrd.PhaseReady {
+		log.Info("Waiting for renamed PangolinResource to become Ready", "replaces", resourceNames(olds))
+		return true, nil
+	}
+
+	for _, old := range olds {
+		if err := r.resources().Delete(ctx, old); err != nil && !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to delete renamed PangolinResource %s: %w", old.Name, err)
+		}
+		log.Info("Deleted renamed PangolinResource", "resource", old.Name)
+		r.Recorder.Event(ingress, corev1.EventTypeNormal, "Renamed",
+			fmt.Sprintf("Replaced PangolinResource %s with %s", old.Name, renamed.Name))
+		metrics.ResourceOperationsTotal.WithLabelValues(metrics.OperationDelete).Inc()
+		metrics.StopReadyTimer(old.Namespace + "/" + old.Name)
+	}
+	return false, nil
+}
+
+// successorFor returns another managed Ingress in the namespace serving the
+// host the resource was created for, to hand the resource to when its own
+// Ingress is deleted, as when an Ingress is renamed. It returns nil if there
+// is none or the resource does not record its host.
+func (r *IngressReconciler) successorFor(
+	ctx context.Context,
+	deleted *networkingv1.Ingress,
+	resource *pangolincrd.PangolinResource,
+) (*networkingv1.Ingress, error) {
+	host := resource.Annotations[AnnotationHost]
+	if host == "" {
+		return nil, nil
+	}
+
+	var ingresses networkingv1.IngressList
+	if err := r.List(ctx, &ingresses, client.InNamespace(deleted.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list Ingresses: %w", err)
+	}
+	sort.Slice(ingresses.Items, func(i, j int) bool { return ingresses.Items[i].Name < ingresses.Items[j].Name })
+
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		if ingress.UID == deleted.UID || !ingress.DeletionTimestamp.IsZero() || !servesHost(ingress, host) {
+			continue
+		}
+		class, err := r.resolveIngressClass(ctx, ingress)
+		if err != nil || !r.isManaged(ingress, class) {
+			continue
+		}
+		return ingress, nil
+	}
+	return nil, nil
+}
+
+// servesHost reports whether one of the Ingress rules is for host.
+func servesHost(ingress *networkingv1.Ingress, host string) bool {
+	for _, rule := range ingress.Spec.Rules {
+		if strings.EqualFold(rule.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceNames returns the names of resources.
+func resourceNames(resources []*pangolincrd.PangolinResource) []string {
+	names := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		names = append(names, resource.Name)
+	}
+	return names
+}
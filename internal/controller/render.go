@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// RenderedHost is the outcome of rendering a single Ingress host.
+type RenderedHost struct {
+	// Host is the Ingress host the resource was built for.
+	Host string
+
+	// Resource is the desired PangolinResource, nil if none could be built.
+	Resource *pangolincrd.PangolinResource
+
+	// Err explains why the host cannot be exposed.
+	Err error
+}
+
+// IsManaged reports whether PIC manages the Ingress. A nil class applies the
+// class name conventions only.
+func (r *IngressReconciler) IsManaged(ingress *networkingv1.Ingress, class *IngressClassSettings) bool {
+	return r.isManaged(ingress, class)
+}
+
+// ResolveTunnel returns the tunnel name the Ingress routes through.
+func (r *IngressReconciler) ResolveTunnel(ingress *networkingv1.Ingress, class *IngressClassSettings) (string, error) {
+	return r.resolveTunnel(ingress, class)
+}
+
+// Render builds the PangolinResources PIC would apply for the Ingress, one
+// per unique host, without reading from or writing to the cluster. It runs
+// the same renderHosts step as Reconcile, with the Ingress's expiry and
+// schedule evaluated at the reconciler's clock, so the output matches what
+// the controller would create. Cluster policies and a disabled tunnel are
+// only known in the cluster; Explain accounts for them.
+func (r *IngressReconciler) Render(
+	ingress *networkingv1.Ingress,
+	class *IngressClassSettings,
+	tunnelName string,
+	tunnelNamespace string,
+) []RenderedHost {
+	groups := collectHostPaths(ingress)
+	state := evaluateExposure(ingress, r.now())
+	if state.expired && state.expiryErr == nil && r.config().ExpiryAction == config.ExpiryActionDelete {
+		rendered := make([]RenderedHost, 0, len(groups))
+		for _, group := range groups {
+			rendered = append(rendered, RenderedHost{
+				Host: group.Host,
+				Err:  fmt.Errorf("expired at %s, PangolinResources are deleted", state.expiry.Format(time.RFC3339)),
+			})
+		}
+		return rendered
+	}
+	target := tunnelTarget{name: tunnelName, namespace: tunnelNamespace}
+	return r.renderHosts(ingress, class, groups, target, nil, state.window)
+}
+
+// renderHosts builds the PangolinResource of each host group as Reconcile
+// applies it: turned off when the tunnel is disabled or the schedule is
+// closed, and rejected when it breaks a cluster policy.
+func (r *IngressReconciler) renderHosts(
+	ingress *networkingv1.Ingress,
+	class *IngressClassSettings,
+	groups []HostPathGroup,
+	target tunnelTarget,
+	policy *namespacePolicy,
+	window exposureWindow,
+) []RenderedHost {
+	var rendered []RenderedHost
+	for _, group := range groups {
+		desired, err := r.buildDesiredPangolinResource(ingress, class, group.Host, group.Paths, target.name, target.namespace)
+		if err != nil {
+			rendered = append(rendered, RenderedHost{Host: group.Host, Err: err})
+			continue
+		}
+
+		// A disabled tunnel turns off every resource on it, a closed
+		// schedule those of the Ingress
+		if target.disabled || !window.open {
+			desired.Spec.Enabled = false
+		}
+
+		// Hosts rejected by a cluster policy are not exposed
+		if err := policy.checkResource(desired); err != nil {
+			rendered = append(rendered, RenderedHost{Host: group.Host, Resource: desired, Err: err})
+			continue
+		}
+
+		// Set owner reference for garbage collection
+		if err := r.setController(ingress, desired); err != nil {
+			rendered = append(rendered, RenderedHost{
+				Host: group.Host,
+				Err:  fmt.Errorf("failed to set owner reference: %w", err),
+			})
+			continue
+		}
+		desired.SetGroupVersionKind(pangolincrd.GroupVersion.WithKind("PangolinResource"))
+		rendered = append(rendered, RenderedHost{Host: group.Host, Resource: desired})
+	}
+	return rendered
+}
+
+// SplitTunnelRef splits a tunnel reference, "name" or "namespace/name". The
+// namespace is empty for a bare name.
+func SplitTunnelRef(ref string) (string, string, error) {
+	return parseTunnelRef(ref)
+}
+
+// DiffPangolinResource returns the field-level differences between a live
+// PangolinResource and a desired one, as reported in DriftDetected events.
+func DiffPangolinResource(existing, desired *pangolincrd.PangolinResource) ([]string, error) {
+	return detectDrift(existing, desired)
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/util"
+)
+
+const (
+	// AnnotationSchedule limits when an Ingress is exposed to a recurring
+	// window, a cron expression or time ranges (see util.ParseSchedule).
+	// Outside the window its PangolinResources are disabled.
+	AnnotationSchedule = "pangolin.ingress.k8s.io/schedule"
+
+	// AnnotationScheduleTimezone is the IANA time zone AnnotationSchedule is
+	// evaluated in, UTC by default.
+	AnnotationScheduleTimezone = "pangolin.ingress.k8s.io/schedule-timezone"
+
+	// scheduleSlack delays the requeue at a schedule transition or an expiry
+	// so the reconcile runs just after it.
+	scheduleSlack = time.Second
+)
+
+// exposureWindow is the state of an Ingress's schedule at a point in time.
+type exposureWindow struct {
+	// scheduled reports that the Ingress has a schedule.
+	scheduled bool
+
+	// open reports whether the Ingress is exposed; true without a schedule.
+	open bool
+
+	// next is the next transition, zero if there is none.
+	next time.Time
+
+	// err explains why the schedule could not be parsed; the window is
+	// closed then, so a broken schedule never exposes the Ingress.
+	err error
+}
+
+// evaluateSchedule evaluates the Ingress's schedule at now. It depends on
+// nothing but the annotations and the clock, so after a restart the first
+// reconcile restores the state and the requeue.
+func evaluateSchedule(ingress *networkingv1.Ingress, now time.Time) exposureWindow {
+	spec, ok := ingress.Annotations[AnnotationSchedule]
+	if !ok {
+		return exposureWindow{open: true}
+	}
+	schedule, err := util.ParseSchedule(spec, ingress.Annotations[AnnotationScheduleTimezone])
+	if err != nil {
+		return exposureWindow{scheduled: true, err: err}
+	}
+	window := exposureWindow{scheduled: true, open: schedule.Active(now)}
+	if next, ok := schedule.NextTransition(now); ok {
+		window.next = next.In(schedule.Location())
+	}
+	return window
+}
+
+// requeueAt shortens result's requeue so the Ingress is reconciled just
+// after at. A zero at leaves result unchanged.
+func requeueAt(result *ctrl.Result, now, at time.Time) {
+	if at.IsZero() {
+		return
+	}
+	delay := at.Sub(now) + scheduleSlack
+	if result.RequeueAfter == 0 || delay < result.RequeueAfter {
+		result.RequeueAfter = delay
+	}
+}
+
+// describe summarizes the window for events and pic explain.
+func (w exposureWindow) describe() string {
+	state := "hidden"
+	if w.open {
+		state = "exposed"
+	}
+	switch {
+	case w.err != nil:
+		return fmt.Sprintf("hidden: %v", w.err)
+	case w.next.IsZero():
+		return fmt.Sprintf("%s, the schedule does not change within a year", state)
+	default:
+		return fmt.Sprintf("%s until %s", state, w.next.Format(time.RFC3339))
+	}
+}
+
+// now returns the current time from Clock, or time.Now if it is unset.
+func (r *IngressReconciler) now() time.Time {
+	if r.Clock != nil {
+		return r.Clock()
+	}
+	return time.Now()
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// Several PIC instances can share a cluster, each managing the Ingresses
+// matching its shardSelector and using one of its shardTunnels. An Ingress
+// outside the shard is left to the instance it belongs to: unlike an
+// unmanaged Ingress, its PangolinResources are not changed, and only those
+// labeled with this instance's ID are deleted. A deleted Ingress is
+// finalized whatever its shard.
+
+// shardSelector returns the label selector of the instance's shard.
+func (r *IngressReconciler) shardSelector() labels.Selector {
+	selector, err := labels.Parse(r.config().ShardSelector)
+	if err != nil {
+		// Validated with the configuration
+		return labels.Nothing()
+	}
+	return selector
+}
+
+// outsideShard explains why the object's labels are not selected by the
+// shard, or returns "" if they are.
+func (r *IngressReconciler) outsideShard(obj client.Object) string {
+	selector := r.shardSelector()
+	if selector.Matches(labels.Set(obj.GetLabels())) {
+		return ""
+	}
+	return fmt.Sprintf("labels do not match shardSelector %q", selector.String())
+}
+
+// tunnelOutsideShard explains why the tunnel is not one of the shard's
+// tunnels, or returns "" if it is. Bare names in shardTunnels match the
+// tunnel in any namespace.
+func (r *IngressReconciler) tunnelOutsideShard(target tunnelTarget) string {
+	tunnels := r.config().ShardTunnels
+	if len(tunnels) == 0 {
+		return ""
+	}
+	for _, ref := range tunnels {
+		namespace, name, qualified := strings.Cut(ref, "/")
+		if !qualified {
+			namespace, name = "", ref
+		}
+		if name == target.name && (namespace == "" || namespace == target.namespace) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("tunnel %s/%s is not in shardTunnels %s",
+		target.namespace, target.name, strings.Join(tunnels, ", "))
+}
+
+// leftShard returns the resources of an Ingress outside the shard that carry
+// this instance's ID. The instance whose shard the Ingress moved to leaves
+// them alone as another instance's, so they are withdrawn for it to create
+// its own. Without an instanceID the resources are not told apart from the
+// other instance's; they are kept for it to take over.
+func (r *IngressReconciler) leftShard(owned []*pangolincrd.PangolinResource) []*pangolincrd.PangolinResource {
+	id := r.config().InstanceID
+	if id == "" {
+		return nil
+	}
+	var labeled []*pangolincrd.PangolinResource
+	for _, resource := range owned {
+		if resource.Labels[LabelInstance] == id {
+			labeled = append(labeled, resource)
+		}
+	}
+	return labeled
+}
+
+// inShard passes events for Ingresses selected by the shard, read at event
+// time so a reloaded selector applies at once. Updates pass if either
+// version is selected, so an Ingress relabeled out of the shard is seen
+// leaving it, and for deleted Ingresses holding the cleanup finalizer, which
+// every instance finalizes.
+func (r *IngressReconciler) inShard() predicate.Predicate {
+	selected := func(obj client.Object) bool {
+		return r.outsideShard(obj) == ""
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return selected(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return selected(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return selected(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			deleted := e.ObjectNew.GetDeletionTimestamp() != nil &&
+				controllerutil.ContainsFinalizer(e.ObjectNew, FinalizerCleanup)
+			return selected(e.ObjectOld) || selected(e.ObjectNew) || deleted
+		},
+	}
+}
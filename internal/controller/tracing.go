@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// AnnotationTraceParent carries the W3C traceparent of the reconcile that
+// last changed a PangolinResource, so pangolin-operator can continue the trace.
+const AnnotationTraceParent = "pic.ingress.k8s.io/traceparent"
+
+// tracer is a no-op until tracing.Setup installs a tracer provider.
+var tracer = otel.Tracer("github.com/wizzz/pangolin-ingress-controller/internal/controller")
+
+// Span attribute keys.
+const (
+	attrIngressNamespace = attribute.Key("pic.ingress.namespace")
+	attrIngressName      = attribute.Key("pic.ingress.name")
+	attrHost             = attribute.Key("pic.host")
+	attrResource         = attribute.Key("pic.pangolinresource")
+	attrTunnel           = attribute.Key("pic.tunnel")
+)
+
+// ingressAttributes returns the span attributes identifying an Ingress.
+func ingressAttributes(ingress *networkingv1.Ingress) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attrIngressNamespace.String(ingress.Namespace),
+		attrIngressName.String(ingress.Name),
+	}
+}
+
+// resourceHost returns the public host a PangolinResource serves.
+func resourceHost(resource *pangolincrd.PangolinResource) string {
+	if resource.Spec.HTTPConfig == nil {
+		return ""
+	}
+	if resource.Spec.HTTPConfig.Subdomain == "" {
+		return resource.Spec.HTTPConfig.DomainName
+	}
+	return resource.Spec.HTTPConfig.Subdomain + "." + resource.Spec.HTTPConfig.DomainName
+}
+
+// recordError marks the span as failed.
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// setTraceParent stamps the current trace context on the desired resource.
+// When the resource would otherwise be unchanged, the existing traceparent is
+// kept so that reconciles without changes do not rewrite the resource.
+func setTraceParent(ctx context.Context, desired, existing *pangolincrd.PangolinResource) {
+	if existing != nil {
+		if diffs, err := detectDrift(existing, desired); err == nil && len(diffs) == 0 {
+			if traceParent, ok := existing.Annotations[AnnotationTraceParent]; ok {
+				setAnnotation(desired, AnnotationTraceParent, traceParent)
+			}
+			return
+		}
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if traceParent := carrier.Get("traceparent"); traceParent != "" {
+		setAnnotation(desired, AnnotationTraceParent, traceParent)
+	}
+}
+
+func setAnnotation(resource *pangolincrd.PangolinResource, key, value string) {
+	if resource.Annotations == nil {
+		resource.Annotations = make(map[string]string)
+	}
+	resource.Annotations[key] = value
+}
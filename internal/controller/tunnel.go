@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
+)
+
+var (
+	// errTunnelNotFound is returned when no PangolinTunnel matches a reference.
+	errTunnelNotFound = errors.New("tunnel not found")
+
+	// errTunnelNotGranted is returned when a cross-namespace tunnel reference
+	// is not allowed by a PangolinTunnelGrant.
+	errTunnelNotGranted = errors.New("tunnel reference not permitted")
+)
+
+// parseTunnelRef splits a tunnel reference, "name" or "namespace/name".
+// The namespace is empty for a bare name.
+func parseTunnelRef(ref string) (string, string, error) {
+	namespace, name, qualified := strings.Cut(ref, "/")
+	if !qualified {
+		return "", ref, nil
+	}
+	if namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid tunnel reference %q, expected <name> or <namespace>/<name>", ref)
+	}
+	return namespace, name, nil
+}
+
+// tunnelTarget is a resolved tunnel reference.
+type tunnelTarget struct {
+	name      string
+	namespace string
+
+	// ambiguous lists the other namespaces holding a usable tunnel of the
+	// same name when no preference entry picked namespace.
+	ambiguous []string
+
+	// disabled reports that the tunnel carries AnnotationDisabled.
+	disabled bool
+}
+
+// validateTunnel resolves a tunnel reference for an Ingress in
+// ingressNamespace.
+//
+// A "namespace/name" reference names the tunnel exactly. A bare name is looked
+// up in the namespaces holding a tunnel of that name, ordered by
+// tunnelPreference, skipping those the Ingress may not reference.
+// Cross-namespace references must be allowed by tunnelGranted.
+func (r *IngressReconciler) validateTunnel(
+	ctx context.Context,
+	ingressNamespace string,
+	ref string,
+) (tunnelTarget, error) {
+	ctx, span := tracer.Start(ctx, "validateTunnel", trace.WithAttributes(attrTunnel.String(ref)))
+	defer span.End()
+
+	namespace, name, err := parseTunnelRef(ref)
+	if err != nil {
+		return tunnelTarget{}, err
+	}
+
+	var candidates []string
+	if namespace != "" {
+		var tunnel pangolincrd.PangolinTunnel
+		if err := r.resources().Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &tunnel); err != nil {
+			if apierrors.IsNotFound(err) {
+				return tunnelTarget{}, fmt.Errorf("%w: %s/%s", errTunnelNotFound, namespace, name)
+			}
+			return tunnelTarget{}, fmt.Errorf("failed to get tunnel %s/%s: %w", namespace, name, err)
+		}
+		candidates = []string{namespace}
+	} else {
+		if candidates, err = r.tunnelNamespaces(ctx, ingressNamespace, name); err != nil {
+			return tunnelTarget{}, err
+		}
+		if len(candidates) == 0 {
+			return tunnelTarget{}, fmt.Errorf("%w: %q", errTunnelNotFound, name)
+		}
+	}
+
+	var permitted []string
+	for _, candidate := range candidates {
+		granted := candidate == ingressNamespace
+		if !granted {
+			if granted, err = r.tunnelGranted(ctx, ingressNamespace, candidate, name); err != nil {
+				return tunnelTarget{}, err
+			}
+		}
+		if granted {
+			permitted = append(permitted, candidate)
+		}
+	}
+	if len(permitted) == 0 {
+		return tunnelTarget{}, fmt.Errorf("%w: namespace %s may not reference tunnel %s/%s, no PangolinTunnelGrant allows it",
+			errTunnelNotGranted, ingressNamespace, candidates[0], name)
+	}
+
+	target := tunnelTarget{name: name, namespace: permitted[0]}
+	if len(permitted) > 1 && r.tunnelRank(ingressNamespace, target.namespace) == len(r.config().TunnelPreference) {
+		target.ambiguous = permitted[1:]
+	}
+
+	var tunnel pangolincrd.PangolinTunnel
+	if err := r.resources().Get(ctx, types.NamespacedName{Namespace: target.namespace, Name: name}, &tunnel); err != nil {
+		if apierrors.IsNotFound(err) {
+			return tunnelTarget{}, fmt.Errorf("%w: %s/%s", errTunnelNotFound, target.namespace, name)
+		}
+		return tunnelTarget{}, fmt.Errorf("failed to get tunnel %s/%s: %w", target.namespace, name, err)
+	}
+	target.disabled = isDisabled(&tunnel)
+	return target, nil
+}
+
+// tunnelNamespaces returns the namespaces holding a PangolinTunnel called
+// name, ordered by tunnelRank and then by name. The lookup uses
+// TunnelNameIndex rather than scanning every tunnel.
+func (r *IngressReconciler) tunnelNamespaces(ctx context.Context, ingressNamespace, name string) ([]string, error) {
+	var tunnelList pangolincrd.PangolinTunnelList
+	if err := r.resources().List(ctx, &tunnelList, client.MatchingFields{TunnelNameIndex: name}); err != nil {
+		return nil, fmt.Errorf("failed to list tunnels named %q: %w", name, err)
+	}
+
+	namespaces := make([]string, 0, len(tunnelList.Items))
+	for _, tunnel := range tunnelList.Items {
+		namespaces = append(namespaces, tunnel.Namespace)
+	}
+
+	sort.Slice(namespaces, func(i, j int) bool {
+		ri, rj := r.tunnelRank(ingressNamespace, namespaces[i]), r.tunnelRank(ingressNamespace, namespaces[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return namespaces[i] < namespaces[j]
+	})
+	return namespaces, nil
+}
+
+// tunnelRank returns the position of namespace in the configured tunnel
+// preference, or the length of the preference if it is not listed.
+func (r *IngressReconciler) tunnelRank(ingressNamespace, namespace string) int {
+	cfg := r.config()
+	for i, entry := range cfg.TunnelPreference {
+		switch entry {
+		case config.PreferIngressNamespace:
+			entry = ingressNamespace
+		case config.PreferControllerNamespace:
+			entry = cfg.ControllerNamespace
+		}
+		if entry != "" && entry == namespace {
+			return i
+		}
+	}
+	return len(cfg.TunnelPreference)
+}
+
+// tunnelGranted reports whether Ingresses in from may reference the tunnel
+// namespace/name. Grants are read next to the tunnels, from the management
+// cluster in multi-cluster mode. A namespace without PangolinTunnelGrants
+// denies every reference unless RequireTunnelGrants is turned off.
+func (r *IngressReconciler) tunnelGranted(ctx context.Context, from, namespace, name string) (bool, error) {
+	var grants piccrd.PangolinTunnelGrantList
+	if err := r.resources().List(ctx, &grants, client.InNamespace(namespace)); err != nil {
+		return false, fmt.Errorf("failed to list PangolinTunnelGrants in %s: %w", namespace, err)
+	}
+	if len(grants.Items) == 0 {
+		return !r.config().RequireTunnelGrants, nil
+	}
+
+	for _, grant := range grants.Items {
+		if grantAllows(&grant.Spec, from, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// grantAllows reports whether a grant lets namespace from reference the named tunnel.
+func grantAllows(spec *piccrd.PangolinTunnelGrantSpec, from, name string) bool {
+	fromAllowed := false
+	for _, f := range spec.From {
+		if f.Namespace == from {
+			fromAllowed = true
+			break
+		}
+	}
+	if !fromAllowed {
+		return false
+	}
+
+	if len(spec.To) == 0 {
+		return true
+	}
+	for _, to := range spec.To {
+		if to.Name == name {
+			return true
+		}
+	}
+	return false
+}
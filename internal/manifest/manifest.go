@@ -0,0 +1,139 @@
+// Package manifest reads Ingresses from and writes PangolinResources to
+// Kubernetes YAML or JSON manifests, for the pic CLI.
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// ReadIngresses decodes every Ingress found in the given files. A path of "-"
+// (or no path at all) reads from stdin. Other kinds are skipped and items of
+// a List are expanded. Ingresses without a namespace get defaultNamespace.
+func ReadIngresses(paths []string, defaultNamespace string) ([]networkingv1.Ingress, error) {
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	var ingresses []networkingv1.Ingress
+	for _, path := range paths {
+		found, err := readIngressFile(path)
+		if err != nil {
+			return nil, err
+		}
+		ingresses = append(ingresses, found...)
+	}
+
+	for i := range ingresses {
+		if ingresses[i].Namespace == "" {
+			ingresses[i].Namespace = defaultNamespace
+		}
+	}
+	return ingresses, nil
+}
+
+func readIngressFile(path string) ([]networkingv1.Ingress, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	ingresses, err := DecodeIngresses(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return ingresses, nil
+}
+
+// DecodeIngresses decodes every Ingress in a stream of YAML documents or
+// JSON objects. Other kinds are skipped and items of a List are expanded.
+func DecodeIngresses(in io.Reader) ([]networkingv1.Ingress, error) {
+	var ingresses []networkingv1.Ingress
+	decoder := utilyaml.NewYAMLOrJSONDecoder(in, 4096)
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return ingresses, nil
+			}
+			return nil, err
+		}
+
+		found, err := ingressesFromDocument(doc)
+		if err != nil {
+			return nil, err
+		}
+		ingresses = append(ingresses, found...)
+	}
+}
+
+func ingressesFromDocument(doc map[string]interface{}) ([]networkingv1.Ingress, error) {
+	if doc == nil {
+		return nil, nil
+	}
+
+	switch doc["kind"] {
+	case "Ingress":
+		var ingress networkingv1.Ingress
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(doc, &ingress); err != nil {
+			return nil, err
+		}
+		return []networkingv1.Ingress{ingress}, nil
+	case "List", "IngressList":
+		items, _ := doc["items"].([]interface{})
+		var ingresses []networkingv1.Ingress
+		for _, item := range items {
+			itemDoc, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			found, err := ingressesFromDocument(itemDoc)
+			if err != nil {
+				return nil, err
+			}
+			ingresses = append(ingresses, found...)
+		}
+		return ingresses, nil
+	}
+	return nil, nil
+}
+
+// WriteResource writes a PangolinResource as a YAML document, without status
+// and other server-populated fields. Documents after the first are preceded
+// by a "---" separator.
+func WriteResource(w io.Writer, resource *pangolincrd.PangolinResource, first bool) error {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource)
+	if err != nil {
+		return err
+	}
+	delete(obj, "status")
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+
+	out, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if !first {
+		if _, err := fmt.Fprintln(w, "---"); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(out)
+	return err
+}
@@ -0,0 +1,133 @@
+// Package metrics defines the Prometheus metrics exported by the Pangolin Ingress Controller.
+// All metrics are registered with the controller-runtime registry and served
+// on the manager's metrics endpoint.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "pic"
+
+// Operation label values for ResourceOperationsTotal.
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// Action label values for OrphansSweptTotal.
+const (
+	SweepDelete = "delete"
+	SweepAdopt  = "adopt"
+)
+
+var (
+	// DriftRepairsTotal counts PangolinResources whose manual changes were reverted.
+	DriftRepairsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "drift_repairs_total",
+			Help:      "Number of PangolinResources reverted after being changed outside PIC.",
+		},
+		[]string{"namespace"},
+	)
+
+	// ManagedIngresses tracks the number of Ingresses managed by PIC.
+	ManagedIngresses = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "managed_ingresses",
+			Help:      "Number of Ingresses managed by PIC, by ingress class and tunnel.",
+		},
+		[]string{"class", "tunnel"},
+	)
+
+	// ResourceOperationsTotal counts PangolinResource writes.
+	ResourceOperationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pangolin_resource_operations_total",
+			Help:      "Number of PangolinResources created, updated and deleted by PIC.",
+		},
+		[]string{"operation"},
+	)
+
+	// HostValidationFailuresTotal counts Ingress hosts that could not be exposed.
+	HostValidationFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "host_validation_failures_total",
+			Help:      "Number of Ingress hosts rejected during reconciliation, by reason.",
+		},
+		[]string{"reason"},
+	)
+
+	// TunnelNotFoundTotal counts reconciles that referenced a missing tunnel.
+	// The tunnel is not a label: Ingresses can reference any name, and the
+	// TunnelNotFound event on the Ingress names it.
+	TunnelNotFoundTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tunnel_not_found_total",
+			Help:      "Number of reconciles that referenced a PangolinTunnel that does not exist.",
+		},
+	)
+
+	// OrphansSweptTotal counts PangolinResources the orphan sweep deleted or
+	// handed back to an Ingress.
+	OrphansSweptTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orphans_swept_total",
+			Help:      "Number of PangolinResources without a valid owner Ingress deleted or adopted by the orphan sweep.",
+		},
+		[]string{"action"},
+	)
+
+	// ExpiringIngresses counts the Ingresses with a pending expiry. It is an
+	// aggregate rather than a series per Ingress to bound its cardinality;
+	// pic explain shows the expiry of a single Ingress.
+	ExpiringIngresses = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "expiring_ingresses",
+			Help:      "Number of Ingresses with an expiry annotation that have not expired yet.",
+		},
+	)
+
+	// NextIngressExpiry is the earliest pending expiry of an Ingress.
+	NextIngressExpiry = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "ingress_next_expiry_timestamp_seconds",
+			Help:      "Unix time of the earliest pending Ingress expiry, 0 if there is none.",
+		},
+	)
+
+	// ResourceReadyDuration observes the time from PIC applying a change to a
+	// PangolinResource until pangolin-operator reports it Ready.
+	ResourceReadyDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "pangolin_resource_ready_duration_seconds",
+			Help:      "Time from an Ingress change being applied until the PangolinResource is Ready.",
+			Buckets:   []float64{1, 2, 5, 10, 30, 60, 120, 300, 600, 1800},
+		},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		DriftRepairsTotal,
+		ManagedIngresses,
+		ResourceOperationsTotal,
+		HostValidationFailuresTotal,
+		TunnelNotFoundTotal,
+		OrphansSweptTotal,
+		ExpiringIngresses,
+		NextIngressExpiry,
+		ResourceReadyDuration,
+	)
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// ingressLabels are the ManagedIngresses labels of a single Ingress.
+type ingressLabels struct {
+	class  string
+	tunnel string
+}
+
+var (
+	managedMu sync.Mutex
+	managed   = make(map[string]ingressLabels)
+
+	readyMu      sync.Mutex
+	readyPending = make(map[string]time.Time)
+
+	expiryMu sync.Mutex
+	expiries = make(map[string]time.Time)
+)
+
+// SetIngressManaged records that the Ingress identified by key is managed
+// with the given class and tunnel, updating ManagedIngresses.
+func SetIngressManaged(key, class, tunnel string) {
+	managedMu.Lock()
+	defer managedMu.Unlock()
+
+	next := ingressLabels{class: class, tunnel: tunnel}
+	if prev, ok := managed[key]; ok {
+		if prev == next {
+			return
+		}
+		ManagedIngresses.WithLabelValues(prev.class, prev.tunnel).Dec()
+	}
+	managed[key] = next
+	ManagedIngresses.WithLabelValues(class, tunnel).Inc()
+}
+
+// SetIngressUnmanaged removes the Ingress identified by key from ManagedIngresses.
+func SetIngressUnmanaged(key string) {
+	managedMu.Lock()
+	defer managedMu.Unlock()
+
+	if prev, ok := managed[key]; ok {
+		ManagedIngresses.WithLabelValues(prev.class, prev.tunnel).Dec()
+		delete(managed, key)
+	}
+}
+
+// SetIngressExpiry records when the Ingress identified by key expires,
+// updating ExpiringIngresses and NextIngressExpiry; the zero time removes it.
+func SetIngressExpiry(key string, at time.Time) {
+	expiryMu.Lock()
+	defer expiryMu.Unlock()
+
+	if at.IsZero() {
+		delete(expiries, key)
+	} else {
+		expiries[key] = at
+	}
+
+	var next time.Time
+	for _, expiry := range expiries {
+		if next.IsZero() || expiry.Before(next) {
+			next = expiry
+		}
+	}
+	ExpiringIngresses.Set(float64(len(expiries)))
+	if next.IsZero() {
+		NextIngressExpiry.Set(0)
+	} else {
+		NextIngressExpiry.Set(float64(next.Unix()))
+	}
+}
+
+// StartReadyTimer starts timing a PangolinResource change. A timer that is
+// already running keeps its original start time.
+func StartReadyTimer(key string) {
+	readyMu.Lock()
+	defer readyMu.Unlock()
+
+	if _, ok := readyPending[key]; !ok {
+		readyPending[key] = time.Now()
+	}
+}
+
+// ObserveReady records ResourceReadyDuration for a running timer once the
+// resource is ready.
+func ObserveReady(key string) {
+	readyMu.Lock()
+	defer readyMu.Unlock()
+
+	if start, ok := readyPending[key]; ok {
+		ResourceReadyDuration.Observe(time.Since(start).Seconds())
+		delete(readyPending, key)
+	}
+}
+
+// StopReadyTimer discards a running timer, e.g. when the resource is deleted.
+func StopReadyTimer(key string) {
+	readyMu.Lock()
+	defer readyMu.Unlock()
+
+	delete(readyPending, key)
+}
@@ -91,6 +91,13 @@ type Target struct {
 	Priority int32 `json:"priority,omitempty"`
 }
 
+// Phases reported by pangolin-operator in resource and tunnel status.
+const (
+	PhasePending = "Pending"
+	PhaseReady   = "Ready"
+	PhaseFailed  = "Failed"
+)
+
 // PangolinResourceStatus defines the observed state of PangolinResource.
 // This is set by pangolin-operator, read-only for PIC.
 type PangolinResourceStatus struct {
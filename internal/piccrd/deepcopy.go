@@ -0,0 +1,336 @@
+package piccrd
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinIngressClassConfig) DeepCopyInto(out *PangolinIngressClassConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy creates a deep copy of PangolinIngressClassConfig.
+func (in *PangolinIngressClassConfig) DeepCopy() *PangolinIngressClassConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PangolinIngressClassConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy as runtime.Object.
+func (in *PangolinIngressClassConfig) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinIngressClassConfigSpec) DeepCopyInto(out *PangolinIngressClassConfigSpec) {
+	*out = *in
+	if in.SSO != nil {
+		out.SSO = new(bool)
+		*out.SSO = *in.SSO
+	}
+	if in.BlockAccess != nil {
+		out.BlockAccess = new(bool)
+		*out.BlockAccess = *in.BlockAccess
+	}
+	if in.AllowedDomains != nil {
+		out.AllowedDomains = make([]string, len(in.AllowedDomains))
+		copy(out.AllowedDomains, in.AllowedDomains)
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinIngressClassConfigList) DeepCopyInto(out *PangolinIngressClassConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]PangolinIngressClassConfig, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of PangolinIngressClassConfigList.
+func (in *PangolinIngressClassConfigList) DeepCopy() *PangolinIngressClassConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(PangolinIngressClassConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy as runtime.Object.
+func (in *PangolinIngressClassConfigList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinNamespacedIngressClassConfig) DeepCopyInto(out *PangolinNamespacedIngressClassConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy creates a deep copy of PangolinNamespacedIngressClassConfig.
+func (in *PangolinNamespacedIngressClassConfig) DeepCopy() *PangolinNamespacedIngressClassConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PangolinNamespacedIngressClassConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy as runtime.Object.
+func (in *PangolinNamespacedIngressClassConfig) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinNamespacedIngressClassConfigList) DeepCopyInto(out *PangolinNamespacedIngressClassConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]PangolinNamespacedIngressClassConfig, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of PangolinNamespacedIngressClassConfigList.
+func (in *PangolinNamespacedIngressClassConfigList) DeepCopy() *PangolinNamespacedIngressClassConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(PangolinNamespacedIngressClassConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy as runtime.Object.
+func (in *PangolinNamespacedIngressClassConfigList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinIngressPolicy) DeepCopyInto(out *PangolinIngressPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy creates a deep copy of PangolinIngressPolicy.
+func (in *PangolinIngressPolicy) DeepCopy() *PangolinIngressPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PangolinIngressPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy as runtime.Object.
+func (in *PangolinIngressPolicy) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinIngressPolicySpec) DeepCopyInto(out *PangolinIngressPolicySpec) {
+	*out = *in
+	if in.SSO != nil {
+		out.SSO = new(bool)
+		*out.SSO = *in.SSO
+	}
+	if in.BlockAccess != nil {
+		out.BlockAccess = new(bool)
+		*out.BlockAccess = *in.BlockAccess
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinIngressPolicyList) DeepCopyInto(out *PangolinIngressPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]PangolinIngressPolicy, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of PangolinIngressPolicyList.
+func (in *PangolinIngressPolicyList) DeepCopy() *PangolinIngressPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(PangolinIngressPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy as runtime.Object.
+func (in *PangolinIngressPolicyList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinClusterPolicy) DeepCopyInto(out *PangolinClusterPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy creates a deep copy of PangolinClusterPolicy.
+func (in *PangolinClusterPolicy) DeepCopy() *PangolinClusterPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PangolinClusterPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy as runtime.Object.
+func (in *PangolinClusterPolicy) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinClusterPolicySpec) DeepCopyInto(out *PangolinClusterPolicySpec) {
+	*out = *in
+	if in.NamespaceRules != nil {
+		out.NamespaceRules = make([]NamespaceRule, len(in.NamespaceRules))
+		for i := range in.NamespaceRules {
+			in.NamespaceRules[i].DeepCopyInto(&out.NamespaceRules[i])
+		}
+	}
+	if in.TunnelRules != nil {
+		out.TunnelRules = make([]TunnelRule, len(in.TunnelRules))
+		for i := range in.TunnelRules {
+			in.TunnelRules[i].DeepCopyInto(&out.TunnelRules[i])
+		}
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *NamespaceRule) DeepCopyInto(out *NamespaceRule) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	if in.AllowedDomains != nil {
+		out.AllowedDomains = make([]string, len(in.AllowedDomains))
+		copy(out.AllowedDomains, in.AllowedDomains)
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *TunnelRule) DeepCopyInto(out *TunnelRule) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinClusterPolicyList) DeepCopyInto(out *PangolinClusterPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]PangolinClusterPolicy, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of PangolinClusterPolicyList.
+func (in *PangolinClusterPolicyList) DeepCopy() *PangolinClusterPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(PangolinClusterPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy as runtime.Object.
+func (in *PangolinClusterPolicyList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinTunnelGrant) DeepCopyInto(out *PangolinTunnelGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy creates a deep copy of PangolinTunnelGrant.
+func (in *PangolinTunnelGrant) DeepCopy() *PangolinTunnelGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(PangolinTunnelGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy as runtime.Object.
+func (in *PangolinTunnelGrant) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinTunnelGrantSpec) DeepCopyInto(out *PangolinTunnelGrantSpec) {
+	*out = *in
+	if in.From != nil {
+		out.From = make([]TunnelGrantFrom, len(in.From))
+		copy(out.From, in.From)
+	}
+	if in.To != nil {
+		out.To = make([]TunnelGrantTo, len(in.To))
+		copy(out.To, in.To)
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PangolinTunnelGrantList) DeepCopyInto(out *PangolinTunnelGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]PangolinTunnelGrant, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of PangolinTunnelGrantList.
+func (in *PangolinTunnelGrantList) DeepCopy() *PangolinTunnelGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(PangolinTunnelGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy as runtime.Object.
+func (in *PangolinTunnelGrantList) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
@@ -0,0 +1,242 @@
+// Package piccrd provides Go types for the CRDs owned by PIC.
+package piccrd
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// KindPangolinIngressClassConfig is the kind referenced from IngressClass
+	// spec.parameters with scope Cluster.
+	KindPangolinIngressClassConfig = "PangolinIngressClassConfig"
+
+	// KindPangolinNamespacedIngressClassConfig is the kind referenced from
+	// IngressClass spec.parameters with scope Namespace.
+	KindPangolinNamespacedIngressClassConfig = "PangolinNamespacedIngressClassConfig"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// PangolinIngressClassConfig holds the settings of an IngressClass handled by
+// PIC. It is referenced from the IngressClass spec.parameters and, like
+// IngressClass, is cluster-scoped.
+type PangolinIngressClassConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PangolinIngressClassConfigSpec `json:"spec,omitempty"`
+}
+
+// PangolinIngressClassConfigSpec defines the settings applied to every
+// Ingress of the class. Ingress annotations take precedence.
+type PangolinIngressClassConfigSpec struct {
+	// TunnelName is the PangolinTunnel used by Ingresses of the class.
+	// +optional
+	TunnelName string `json:"tunnelName,omitempty"`
+
+	// BackendScheme is the protocol for backend services ("http" or "https").
+	// Defaults to the controller configuration.
+	// +optional
+	BackendScheme string `json:"backendScheme,omitempty"`
+
+	// SSO enables SSO authentication unless the Ingress sets the sso annotation.
+	// +optional
+	SSO *bool `json:"sso,omitempty"`
+
+	// BlockAccess blocks unauthenticated access unless the Ingress sets the
+	// block-access annotation.
+	// +optional
+	BlockAccess *bool `json:"blockAccess,omitempty"`
+
+	// AllowedDomains restricts the hosts Ingresses of the class may expose.
+	// A host is allowed if it equals an entry or is a subdomain of one.
+	// Empty allows every domain.
+	// +optional
+	AllowedDomains []string `json:"allowedDomains,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PangolinIngressClassConfigList contains a list of PangolinIngressClassConfig.
+type PangolinIngressClassConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PangolinIngressClassConfig `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced
+
+// PangolinNamespacedIngressClassConfig is a PangolinIngressClassConfig kept
+// in a namespace, so that the team owning the namespace can change the
+// settings of its IngressClass. It is referenced from the IngressClass
+// spec.parameters with scope Namespace.
+type PangolinNamespacedIngressClassConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PangolinIngressClassConfigSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PangolinNamespacedIngressClassConfigList contains a list of
+// PangolinNamespacedIngressClassConfig.
+type PangolinNamespacedIngressClassConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PangolinNamespacedIngressClassConfig `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+
+// PangolinIngressPolicy sets defaults for every Ingress in its namespace.
+// Ingress annotations take precedence; when several policies exist in a
+// namespace they are applied in name order, later ones overriding earlier ones.
+type PangolinIngressPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PangolinIngressPolicySpec `json:"spec,omitempty"`
+}
+
+// PangolinIngressPolicySpec defines the defaults applied to Ingresses in the namespace.
+type PangolinIngressPolicySpec struct {
+	// TunnelName is the default PangolinTunnel.
+	// +optional
+	TunnelName string `json:"tunnelName,omitempty"`
+
+	// SSO is the default for the sso annotation.
+	// +optional
+	SSO *bool `json:"sso,omitempty"`
+
+	// BlockAccess is the default for the block-access annotation.
+	// +optional
+	BlockAccess *bool `json:"blockAccess,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PangolinIngressPolicyList contains a list of PangolinIngressPolicy.
+type PangolinIngressPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PangolinIngressPolicy `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// PangolinClusterPolicy constrains how Ingresses may be exposed across the
+// cluster. Every policy applies; a host or Ingress that violates any rule is
+// not exposed.
+type PangolinClusterPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PangolinClusterPolicySpec `json:"spec,omitempty"`
+}
+
+// PangolinClusterPolicySpec defines the rules of a cluster policy.
+type PangolinClusterPolicySpec struct {
+	// NamespaceRules constrain the Ingresses of the namespaces they select.
+	// +optional
+	NamespaceRules []NamespaceRule `json:"namespaceRules,omitempty"`
+
+	// TunnelRules restrict which namespaces may route through a tunnel.
+	// +optional
+	TunnelRules []TunnelRule `json:"tunnelRules,omitempty"`
+}
+
+// NamespaceRule constrains the Ingresses of the selected namespaces.
+type NamespaceRule struct {
+	// Name identifies the rule in PolicyViolation events.
+	Name string `json:"name"`
+
+	// NamespaceSelector selects the namespaces the rule applies to. An empty
+	// selector selects every namespace; use the kubernetes.io/metadata.name
+	// label to select a namespace by name.
+	// +optional
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// RequireSSO rejects hosts exposed without SSO.
+	// +optional
+	RequireSSO bool `json:"requireSSO,omitempty"`
+
+	// RequireBlockAccess rejects hosts exposed without block-access.
+	// +optional
+	RequireBlockAccess bool `json:"requireBlockAccess,omitempty"`
+
+	// AllowedDomains restricts the hosts that may be exposed. "example.com"
+	// allows the domain and its subdomains, "*.example.com" only its
+	// subdomains. Empty allows every domain.
+	// +optional
+	AllowedDomains []string `json:"allowedDomains,omitempty"`
+}
+
+// TunnelRule restricts a tunnel to the selected namespaces.
+type TunnelRule struct {
+	// Tunnel is the PangolinTunnel the rule applies to, by name or as
+	// namespace/name.
+	Tunnel string `json:"tunnel"`
+
+	// NamespaceSelector selects the namespaces allowed to use the tunnel.
+	// When several rules name the same tunnel, a namespace selected by any
+	// of them is allowed.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+}
+
+// +kubebuilder:object:root=true
+
+// PangolinClusterPolicyList contains a list of PangolinClusterPolicy.
+type PangolinClusterPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PangolinClusterPolicy `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+
+// PangolinTunnelGrant allows Ingresses in other namespaces to reference
+// PangolinTunnels in the grant's namespace, like a Gateway API ReferenceGrant.
+// Cross-namespace references to a tunnel need one, unless requireTunnelGrants
+// is turned off and the tunnel's namespace contains no grant.
+type PangolinTunnelGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PangolinTunnelGrantSpec `json:"spec,omitempty"`
+}
+
+// PangolinTunnelGrantSpec lists who may reference which tunnels.
+type PangolinTunnelGrantSpec struct {
+	// From lists the namespaces whose Ingresses may reference the tunnels.
+	From []TunnelGrantFrom `json:"from"`
+
+	// To lists the tunnels that may be referenced. Empty grants every
+	// tunnel in the namespace.
+	// +optional
+	To []TunnelGrantTo `json:"to,omitempty"`
+}
+
+// TunnelGrantFrom identifies a namespace allowed to reference tunnels.
+type TunnelGrantFrom struct {
+	// Namespace is the Ingress namespace.
+	Namespace string `json:"namespace"`
+}
+
+// TunnelGrantTo identifies a tunnel that may be referenced.
+type TunnelGrantTo struct {
+	// Name is the PangolinTunnel name.
+	Name string `json:"name"`
+}
+
+// +kubebuilder:object:root=true
+
+// PangolinTunnelGrantList contains a list of PangolinTunnelGrant.
+type PangolinTunnelGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PangolinTunnelGrant `json:"items"`
+}
@@ -0,0 +1,79 @@
+// Package tracing configures OpenTelemetry tracing for the Pangolin Ingress Controller.
+//
+// Tracing is off unless an OTLP endpoint is configured through the standard
+// OpenTelemetry environment variables:
+//
+//	OTEL_EXPORTER_OTLP_ENDPOINT          e.g. http://otel-collector:4318
+//	OTEL_EXPORTER_OTLP_TRACES_ENDPOINT   overrides the endpoint for traces only
+//	OTEL_EXPORTER_OTLP_HEADERS           extra headers, e.g. for authentication
+//	OTEL_SERVICE_NAME                    defaults to pangolin-ingress-controller
+//	OTEL_TRACES_SAMPLER(_ARG)            sampling, defaults to parentbased_always_on
+//	OTEL_SDK_DISABLED=true               turns tracing off
+//
+// Spans are exported over OTLP/HTTP and the W3C trace context propagator is
+// installed globally.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// DefaultServiceName is reported when OTEL_SERVICE_NAME is not set.
+const DefaultServiceName = "pangolin-ingress-controller"
+
+// Enabled reports whether the environment configures an OTLP trace endpoint.
+func Enabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup installs the global tracer provider and propagator. When tracing is
+// not enabled the global no-op provider is kept. The returned function
+// flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context, version string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceName(DefaultServiceName),
+			semconv.ServiceVersion(version),
+		),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
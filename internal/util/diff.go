@@ -0,0 +1,137 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// unset marks a field that is absent on one side of a comparison.
+type unset struct{}
+
+// Unset is how Diff renders the value of a field absent on one side.
+const Unset = "<unset>"
+
+// Diff compares two values through their JSON representation and returns the
+// field-level differences, one entry per leaf field, in the form
+//
+//	<path>: <current> -> <desired>
+//
+// Fields missing on one side are rendered as <unset>. Because the comparison
+// uses JSON field names, new fields are picked up automatically as the
+// compared types grow.
+//
+// # Examples
+//
+//	Diff("spec", {SSO: true}, {SSO: false})  -> ["spec.sso: true -> false"]
+//	Diff("spec", {Targets: [a]}, {Targets: [a, b]}) -> ["spec.targets[1]: <unset> -> {...}"]
+func Diff(path string, current, desired interface{}) ([]string, error) {
+	a, err := toGeneric(current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode current value: %w", err)
+	}
+	b, err := toGeneric(desired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode desired value: %w", err)
+	}
+
+	var diffs []string
+	diffValues(path, a, b, &diffs)
+	return diffs, nil
+}
+
+func toGeneric(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func diffValues(path string, a, b interface{}, diffs *[]string) {
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if aIsMap && bIsMap {
+		keys := make(map[string]bool)
+		for k := range aMap {
+			keys[k] = true
+		}
+		for k := range bMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			av, ok := aMap[k]
+			if !ok {
+				av = unset{}
+			}
+			bv, ok := bMap[k]
+			if !ok {
+				bv = unset{}
+			}
+			diffValues(joinPath(path, k), av, bv, diffs)
+		}
+		return
+	}
+
+	aSlice, aIsSlice := a.([]interface{})
+	bSlice, bIsSlice := b.([]interface{})
+	if aIsSlice && bIsSlice {
+		n := len(aSlice)
+		if len(bSlice) > n {
+			n = len(bSlice)
+		}
+		for i := 0; i < n; i++ {
+			var av, bv interface{} = unset{}, unset{}
+			if i < len(aSlice) {
+				av = aSlice[i]
+			}
+			if i < len(bSlice) {
+				bv = bSlice[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), av, bv, diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: %s -> %s", path, formatValue(a), formatValue(b)))
+	}
+}
+
+// joinPath appends a key to a field path, using bracket notation for keys
+// that contain separators (e.g. label keys).
+func joinPath(path, key string) string {
+	if strings.ContainsAny(key, "./") {
+		return fmt.Sprintf("%s[%s]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func formatValue(v interface{}) string {
+	switch v.(type) {
+	case unset:
+		return Unset
+	case nil:
+		return "null"
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(raw)
+}
@@ -45,13 +45,46 @@ const (
 //   - Lowercase alphanumeric and hyphens only
 //   - Cannot start or end with a hyphen
 func GenerateName(namespace, ingressName, host string) string {
+	return generateName(namespace, namespace, ingressName, host, 4) // 8 characters
+}
+
+// GenerateLongName is GenerateName with the first 8 bytes (16 hex characters)
+// of the hash. It is the fallback when the GenerateName of a host is already
+// taken by a resource for another host.
+func GenerateLongName(namespace, ingressName, host string) string {
+	return generateName(namespace, namespace, ingressName, host, 8)
+}
+
+// GenerateClusterName is GenerateName for an Ingress of the named cluster in
+// multi-cluster mode, where several clusters write to the same namespaces:
+// pic-<cluster>-<namespace>-<ingress>-<hash>, with the cluster in the hash.
+// An empty cluster gives the GenerateName result.
+func GenerateClusterName(cluster, namespace, ingressName, host string) string {
+	if cluster == "" {
+		return GenerateName(namespace, ingressName, host)
+	}
+	return generateName(cluster+"-"+namespace, cluster+"/"+namespace, ingressName, host, 4)
+}
+
+// GenerateLongClusterName is GenerateClusterName with the hash length of
+// GenerateLongName.
+func GenerateLongClusterName(cluster, namespace, ingressName, host string) string {
+	if cluster == "" {
+		return GenerateLongName(namespace, ingressName, host)
+	}
+	return generateName(cluster+"-"+namespace, cluster+"/"+namespace, ingressName, host, 8)
+}
+
+// generateName builds the name "pic-<scope>-<ingress>-<hash>" ending in the
+// first hashBytes of the hash of hashScope, the Ingress name and the host.
+func generateName(scope, hashScope, ingressName, host string, hashBytes int) string {
 	// Create hash from all components for uniqueness
-	hashInput := fmt.Sprintf("%s/%s/%s", namespace, ingressName, host)
+	hashInput := fmt.Sprintf("%s/%s/%s", hashScope, ingressName, host)
 	hash := sha256.Sum256([]byte(hashInput))
-	shortHash := hex.EncodeToString(hash[:4]) // 8 characters
+	shortHash := hex.EncodeToString(hash[:hashBytes])
 
 	// Build the name
-	name := fmt.Sprintf("%s-%s-%s-%s", NamePrefix, namespace, ingressName, shortHash)
+	name := fmt.Sprintf("%s-%s-%s-%s", NamePrefix, scope, ingressName, shortHash)
 
 	// Ensure it's a valid Kubernetes name
 	name = sanitizeName(name)
@@ -59,8 +92,8 @@ func GenerateName(namespace, ingressName, host string) string {
 	// Truncate if necessary (keep the hash at the end)
 	if len(name) > MaxNameLength {
 		// Keep prefix and hash, truncate middle
-		prefixLen := len(NamePrefix) + 1 + len(namespace) + 1 // "pic-namespace-"
-		suffixLen := len(shortHash) + 1                       // "-hash"
+		prefixLen := len(NamePrefix) + 1 + len(scope) + 1 // "pic-namespace-"
+		suffixLen := len(shortHash) + 1                   // "-hash"
 		availableLen := MaxNameLength - prefixLen - suffixLen
 
 		if availableLen > 0 {
@@ -68,7 +101,7 @@ func GenerateName(namespace, ingressName, host string) string {
 			if len(truncatedIngress) > availableLen {
 				truncatedIngress = truncatedIngress[:availableLen]
 			}
-			name = fmt.Sprintf("%s-%s-%s-%s", NamePrefix, namespace, truncatedIngress, shortHash)
+			name = fmt.Sprintf("%s-%s-%s-%s", NamePrefix, scope, truncatedIngress, shortHash)
 		} else {
 			// Extreme case: just use prefix and hash
 			name = fmt.Sprintf("%s-%s", NamePrefix, shortHash)
@@ -0,0 +1,352 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embed the time zone database; the distroless image has none
+	_ "time/tzdata"
+)
+
+// ErrInvalidSchedule is returned by ParseSchedule for a malformed schedule.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// scheduleHorizon bounds the search for the next transition of a schedule.
+const scheduleHorizon = 366 * 24 * time.Hour
+
+// Schedule is a recurring window of time, parsed by ParseSchedule.
+type Schedule struct {
+	loc     *time.Location
+	matcher scheduleMatcher
+}
+
+// scheduleMatcher is implemented by the schedule formats.
+type scheduleMatcher interface {
+	// active reports whether t, in the schedule's location, is inside the window.
+	active(t time.Time) bool
+
+	// next returns the first time after t at which active changes from
+	// current, or the zero time if it does not within scheduleHorizon.
+	next(t time.Time, current bool) time.Time
+}
+
+// ParseSchedule parses a schedule in one of two formats, evaluated in the
+// IANA time zone timezone (UTC if empty):
+//
+// A cron expression with five fields (minute, hour, day of month, month,
+// day of week) is active during every minute it matches:
+//
+//	0-59 9-16 * * Mon-Fri     weekdays from 09:00 to 16:59
+//	0-29 12 * * *             daily from 12:00 to 12:29
+//
+// A list of time ranges separated by ";", each optionally preceded by the
+// days of the week it starts on, is active inside any range. Ranges ending
+// before they start run past midnight:
+//
+//	Mon-Fri 09:00-17:00
+//	Mon-Fri 08:00-12:00; Sat 10:00-14:00
+//	22:00-06:00
+func ParseSchedule(spec, timezone string) (*Schedule, error) {
+	loc := time.UTC
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, timezone)
+		}
+	}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("%w: empty schedule", ErrInvalidSchedule)
+	}
+
+	var (
+		matcher scheduleMatcher
+		err     error
+	)
+	if fields := strings.Fields(spec); len(fields) == 5 && !strings.Contains(spec, ":") {
+		matcher, err = parseCron(fields)
+	} else {
+		matcher, err = parseRanges(spec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchedule, err.Error())
+	}
+	return &Schedule{loc: loc, matcher: matcher}, nil
+}
+
+// Location returns the time zone the schedule is evaluated in.
+func (s *Schedule) Location() *time.Location {
+	return s.loc
+}
+
+// Active reports whether t is inside the schedule's window.
+func (s *Schedule) Active(t time.Time) bool {
+	return s.matcher.active(t.In(s.loc))
+}
+
+// NextTransition returns the first time after t at which Active changes.
+// It returns false if the schedule does not change within a year, as for
+// a window that is always or never active.
+func (s *Schedule) NextTransition(t time.Time) (time.Time, bool) {
+	t = t.In(s.loc)
+	next := s.matcher.next(t, s.matcher.active(t))
+	return next, !next.IsZero()
+}
+
+// cronSchedule is a five-field cron expression.
+type cronSchedule struct {
+	minute, hour, dom, month, dow []bool
+
+	// anyMinute and anyHour are set for fields matching every value, which
+	// let the transition search skip whole hours and days.
+	anyMinute, anyHour bool
+
+	// anyDom and anyDow select the cron rule for restricted day fields: if
+	// both are restricted, a day matching either matches.
+	anyDom, anyDow bool
+}
+
+var dayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+func parseCron(fields []string) (*cronSchedule, error) {
+	c := &cronSchedule{}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	c.dow[0] = c.dow[0] || c.dow[7] // 7 is Sunday too
+	c.anyMinute, c.anyHour = allSet(c.minute, 0), allSet(c.hour, 0)
+	c.anyDom, c.anyDow = fields[2] == "*", fields[4] == "*"
+	return c, nil
+}
+
+// allSet reports whether every value from min on is in set.
+func allSet(set []bool, min int) bool {
+	for _, v := range set[min:] {
+		if !v {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCronField parses a comma-separated list of "*", values, ranges and
+// steps into a set indexed by value.
+func parseCronField(field string, min, max int, names map[string]int) ([]bool, error) {
+	set := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = cronValue(first, min, max, names); err != nil {
+				return nil, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = cronValue(last, min, max, names); err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				hi = max
+			}
+			if hi < lo {
+				return nil, fmt.Errorf("invalid range %q", rangePart)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+func cronValue(s string, min, max int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
+
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	if !c.month[int(t.Month())] {
+		return false
+	}
+	dom, dow := c.dom[t.Day()], c.dow[int(t.Weekday())]
+	switch {
+	case c.anyDom:
+		return dow
+	case c.anyDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func (c *cronSchedule) active(t time.Time) bool {
+	return c.dayMatches(t) && c.hour[t.Hour()] && c.minute[t.Minute()]
+}
+
+// next steps through the minutes after t, skipping days and hours that
+// cannot change the state.
+func (c *cronSchedule) next(t time.Time, current bool) time.Time {
+	loc := t.Location()
+	m := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+	limit := t.Add(scheduleHorizon)
+	for m.Before(limit) {
+		dayOK := c.dayMatches(m)
+		hourOK := dayOK && c.hour[m.Hour()]
+		if (hourOK && c.minute[m.Minute()]) != current {
+			return m
+		}
+
+		nextHour := time.Date(m.Year(), m.Month(), m.Day(), m.Hour()+1, 0, 0, 0, loc)
+		nextDay := time.Date(m.Year(), m.Month(), m.Day()+1, 0, 0, 0, 0, loc)
+		switch {
+		case !current && !dayOK, current && c.anyHour && c.anyMinute:
+			m = nextDay
+		case !current && !hourOK, current && c.anyMinute:
+			m = nextHour
+		default:
+			m = m.Add(time.Minute)
+		}
+	}
+	return time.Time{}
+}
+
+// rangeSchedule is a list of daily time ranges.
+type rangeSchedule []timeRange
+
+// timeRange runs from start to end, in minutes since midnight, on the days
+// of the week it starts on. A range with end before start ends the next day.
+type timeRange struct {
+	days       [7]bool
+	start, end int
+}
+
+func parseRanges(spec string) (rangeSchedule, error) {
+	var ranges rangeSchedule
+	for _, part := range strings.Split(spec, ";") {
+		fields := strings.Fields(part)
+		var r timeRange
+		switch len(fields) {
+		case 1:
+			for i := range r.days {
+				r.days[i] = true
+			}
+		case 2:
+			days, err := parseCronField(strings.ToLower(fields[0]), 0, 6, dayNames)
+			if err != nil {
+				return nil, fmt.Errorf("days %q: %w", fields[0], err)
+			}
+			copy(r.days[:], days)
+			fields = fields[1:]
+		default:
+			return nil, fmt.Errorf("expected [days] HH:MM-HH:MM, got %q", strings.TrimSpace(part))
+		}
+
+		start, end, ok := strings.Cut(fields[0], "-")
+		if !ok {
+			return nil, fmt.Errorf("expected HH:MM-HH:MM, got %q", fields[0])
+		}
+		var err error
+		if r.start, err = parseClock(start); err != nil {
+			return nil, err
+		}
+		if r.end, err = parseClock(end); err != nil {
+			return nil, err
+		}
+		if r.start == r.end || r.start == 24*60 {
+			return nil, fmt.Errorf("empty time range %q", fields[0])
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// parseClock parses HH:MM into minutes since midnight; 24:00 is allowed as an end.
+func parseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(s, ":")
+	h, herr := strconv.Atoi(hh)
+	m, merr := strconv.Atoi(mm)
+	if !ok || herr != nil || merr != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return h*60 + m, nil
+}
+
+func (rs rangeSchedule) active(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	today := int(t.Weekday())
+	yesterday := (today + 6) % 7
+	for _, r := range rs {
+		if r.start < r.end {
+			if r.days[today] && minute >= r.start && minute < r.end {
+				return true
+			}
+			continue
+		}
+		if (r.days[today] && minute >= r.start) || (r.days[yesterday] && minute < r.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// next checks the range boundaries of the coming week in order.
+func (rs rangeSchedule) next(t time.Time, current bool) time.Time {
+	loc := t.Location()
+	var boundaries []time.Time
+	for day := 0; day <= 8; day++ {
+		for _, r := range rs {
+			for _, minute := range []int{r.start, r.end} {
+				b := time.Date(t.Year(), t.Month(), t.Day()+day, 0, minute, 0, 0, loc)
+				if b.After(t) {
+					boundaries = append(boundaries, b)
+				}
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+	for _, b := range boundaries {
+		if rs.active(b) != current {
+			return b
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/util"
+)
+
+func newHandWrittenResource(name, namespace, subdomain, domain string) *pangolincrd.PangolinResource {
+	return &pangolincrd.PangolinResource{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: pangolincrd.PangolinResourceSpec{
+			Name:    name,
+			Enabled: true,
+			HTTPConfig: &pangolincrd.HTTPConfig{
+				Subdomain:  subdomain,
+				DomainName: domain,
+			},
+		},
+	}
+}
+
+func TestAdopt_OptedInResourceIsTakenOver(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+
+	legacy := newHandWrittenResource("legacy-app", "default", "app", "example.com")
+	legacy.Annotations = map[string]string{controller.AnnotationAdopt: "true"}
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress, legacy)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.Len(t, exp.Hosts, 1)
+	host := exp.Hosts[0]
+	assert.Equal(t, "legacy-app", host.ResourceName)
+	assert.True(t, host.Adopt)
+	require.NotNil(t, host.Resource)
+	assert.Empty(t, host.Conflicts)
+}
+
+func TestAdopt_RequiresOptIn(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+
+	// Not opted in, or already controlled by something else
+	legacy := newHandWrittenResource("legacy-app", "default", "app", "example.com")
+	other := newHandWrittenResource("other-app", "default", "app", "example.com")
+	other.Annotations = map[string]string{controller.AnnotationAdopt: "true"}
+	ownedBy(other, newTestIngress("other", "default", "app.example.com"))
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress, legacy, other)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.Len(t, exp.Hosts, 1)
+	host := exp.Hosts[0]
+	assert.NotEqual(t, "legacy-app", host.ResourceName)
+	assert.False(t, host.Adopt)
+	assert.Len(t, host.Conflicts, 2)
+}
+
+func TestAdopt_AdoptedResourceKeepsItsName(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+
+	// The adopt annotation stays on the resource after the adoption
+	adopted := ownedBy(newHandWrittenResource("legacy-app", "default", "app", "example.com"), ingress)
+	adopted.Labels = map[string]string{controller.LabelIngressUID: "ingress-uid"}
+	adopted.Annotations = map[string]string{controller.AnnotationAdopt: "true"}
+
+	r := newFakeReconciler(t, newTestTunnel("default"), newNamespacedTunnel("other", "default"), ingress, adopted)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.Len(t, exp.Hosts, 1)
+	assert.Equal(t, "legacy-app", exp.Hosts[0].ResourceName)
+	assert.False(t, exp.Hosts[0].Adopt)
+	assert.Empty(t, exp.Orphans, "the adopted resource is not replaced")
+
+	// Resources in other namespaces never match
+	other := newTestIngress("myapp", "other", "app.example.com")
+	other.UID = "ingress-uid"
+	exp, err = r.Explain(context.Background(), other)
+	require.NoError(t, err)
+	require.NoError(t, exp.TunnelError)
+	require.Len(t, exp.Hosts, 1)
+	assert.Equal(t, util.GenerateName("other", "myapp", "app.example.com"), exp.Hosts[0].ResourceName)
+	assert.False(t, exp.Hosts[0].Adopt)
+}
@@ -0,0 +1,380 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// fieldPath is a path to a field owned by a field manager, such as
+// ["spec", "httpConfig", "sso"] or ["metadata", "labels", "app"].
+type fieldPath []string
+
+// serverSideApply emulates server-side apply of PangolinResources, which the
+// fake client does not support; other patches go to the fake client.
+//
+// Field ownership is tracked in managedFields like the API server does for a
+// CRD without list-map keys: maps are merged key by key, lists and scalars
+// are owned as a whole. Fields the manager applied before and no longer
+// applies are removed, fields another manager changed conflict unless
+// ownership is forced, and an apply that changes nothing does not write.
+func serverSideApply(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+	options := (&client.PatchOptions{}).ApplyOptions(opts)
+	if options.FieldManager == "" {
+		return apierrors.NewBadRequest("fieldManager is required for apply patches")
+	}
+	force := options.Force != nil && *options.Force
+
+	applied, ok := obj.(*pangolincrd.PangolinResource)
+	if !ok {
+		return fmt.Errorf("server-side apply of %T is not emulated", obj)
+	}
+	config, err := runtime.DefaultUnstructuredConverter.ToUnstructured(applied)
+	if err != nil {
+		return err
+	}
+	appliedFields := configuredFields(config)
+
+	var live pangolincrd.PangolinResource
+	if err := c.Get(ctx, client.ObjectKeyFromObject(applied), &live); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		created := applied.DeepCopy()
+		created.ResourceVersion = ""
+		created.ManagedFields = []metav1.ManagedFieldsEntry{applyEntry(options.FieldManager, appliedFields)}
+		if err := c.Create(ctx, created); err != nil {
+			return err
+		}
+		created.DeepCopyInto(applied)
+		return nil
+	}
+
+	current, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&live)
+	if err != nil {
+		return err
+	}
+
+	// Changed fields owned by other managers conflict, or move to this one
+	var previous []fieldPath
+	var entries []metav1.ManagedFieldsEntry
+	var others []fieldPath
+	for _, entry := range live.ManagedFields {
+		owned := parseFieldsV1(entry.FieldsV1)
+		if entry.Manager == options.FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			previous = owned
+			continue
+		}
+		if entry.Subresource != "" {
+			entries = append(entries, entry)
+			continue
+		}
+		var kept []fieldPath
+		for _, path := range owned {
+			if changed := changedWithin(path, appliedFields, current, config); changed != nil {
+				if !force {
+					return apierrors.NewConflict(schema.GroupResource{Group: pangolincrd.GroupVersion.Group, Resource: "pangolinresources"},
+						live.Name, fmt.Errorf("conflict with %q: .%s", entry.Manager, strings.Join(changed, ".")))
+				}
+				continue
+			}
+			kept = append(kept, path)
+		}
+		others = append(others, kept...)
+		if len(kept) > 0 {
+			entry.FieldsV1 = toFieldsV1(kept)
+			entries = append(entries, entry)
+		}
+	}
+
+	merged := runtime.DeepCopyJSON(current)
+	for _, path := range previous {
+		if !containsPath(appliedFields, path) && !containsPath(others, path) {
+			removeField(merged, path)
+		}
+	}
+	for _, path := range appliedFields {
+		value, _ := getField(config, path)
+		setField(merged, path, runtime.DeepCopyJSONValue(value))
+	}
+	entries = append(entries, applyEntry(options.FieldManager, appliedFields))
+
+	var updated pangolincrd.PangolinResource
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(merged, &updated); err != nil {
+		return err
+	}
+	updated.ManagedFields = entries
+	if reflect.DeepEqual(updated.ObjectMeta, live.ObjectMeta) && reflect.DeepEqual(updated.Spec, live.Spec) {
+		live.DeepCopyInto(applied)
+		return nil
+	}
+	if err := c.Update(ctx, &updated); err != nil {
+		return err
+	}
+	updated.DeepCopyInto(applied)
+	return nil
+}
+
+// configuredFields returns the leaf fields an apply configuration sets:
+// spec, labels, annotations and owner references.
+func configuredFields(obj map[string]interface{}) []fieldPath {
+	var paths []fieldPath
+	if spec, ok := obj["spec"]; ok {
+		paths = leafFields(fieldPath{"spec"}, spec, paths)
+	}
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	for _, key := range []string{"labels", "annotations"} {
+		values, _ := metadata[key].(map[string]interface{})
+		for name := range values {
+			paths = append(paths, fieldPath{"metadata", key, name})
+		}
+	}
+	if _, ok := metadata["ownerReferences"]; ok {
+		paths = append(paths, fieldPath{"metadata", "ownerReferences"})
+	}
+	return paths
+}
+
+func leafFields(path fieldPath, value interface{}, paths []fieldPath) []fieldPath {
+	values, ok := value.(map[string]interface{})
+	if !ok || len(values) == 0 {
+		return append(paths, path)
+	}
+	for key, child := range values {
+		paths = leafFields(append(append(fieldPath{}, path...), key), child, paths)
+	}
+	return paths
+}
+
+// changedWithin returns the first applied field overlapping owned whose
+// value the apply changes, or nil.
+func changedWithin(owned fieldPath, applied []fieldPath, current, config map[string]interface{}) fieldPath {
+	for _, path := range applied {
+		if !hasPrefix(path, owned) && !hasPrefix(owned, path) {
+			continue
+		}
+		before, _ := getField(current, path)
+		after, _ := getField(config, path)
+		if !reflect.DeepEqual(before, after) {
+			return path
+		}
+	}
+	return nil
+}
+
+func hasPrefix(path, prefix fieldPath) bool {
+	return len(path) >= len(prefix) && reflect.DeepEqual([]string(path[:len(prefix)]), []string(prefix))
+}
+
+func containsPath(paths []fieldPath, path fieldPath) bool {
+	for _, p := range paths {
+		if hasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func getField(obj map[string]interface{}, path fieldPath) (interface{}, bool) {
+	var value interface{} = obj
+	for _, key := range path {
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = values[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func setField(obj map[string]interface{}, path fieldPath, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := obj[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			obj[key] = child
+		}
+		obj = child
+	}
+	obj[path[len(path)-1]] = value
+}
+
+func removeField(obj map[string]interface{}, path fieldPath) {
+	parent, ok := getField(obj, path[:len(path)-1])
+	if values, isMap := parent.(map[string]interface{}); ok && isMap {
+		delete(values, path[len(path)-1])
+	}
+}
+
+// applyEntry returns the managedFields entry of an apply by manager.
+func applyEntry(manager string, paths []fieldPath) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: pangolincrd.GroupVersion.String(),
+		FieldsType: "FieldsV1",
+		FieldsV1:   toFieldsV1(paths),
+	}
+}
+
+// toFieldsV1 encodes paths in the managedFields format: {"f:spec":{"f:sso":{}}}.
+func toFieldsV1(paths []fieldPath) *metav1.FieldsV1 {
+	root := make(map[string]interface{})
+	for _, path := range paths {
+		node := root
+		for _, key := range path {
+			child, ok := node["f:"+key].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node["f:"+key] = child
+			}
+			node = child
+		}
+	}
+	raw, _ := json.Marshal(root)
+	return &metav1.FieldsV1{Raw: raw}
+}
+
+// parseFieldsV1 decodes a managedFields entry into the paths it owns. Keyed
+// list entries are taken to own their whole list.
+func parseFieldsV1(fields *metav1.FieldsV1) []fieldPath {
+	if fields == nil {
+		return nil
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(fields.Raw, &root); err != nil {
+		return nil
+	}
+	return ownedFields(nil, root, nil)
+}
+
+func ownedFields(path fieldPath, node map[string]interface{}, paths []fieldPath) []fieldPath {
+	if len(node) == 0 {
+		return append(paths, path)
+	}
+	for key, child := range node {
+		name, isField := strings.CutPrefix(key, "f:")
+		if !isField {
+			// "." or a list entry: the field at path itself
+			return append(paths, path)
+		}
+		childNode, _ := child.(map[string]interface{})
+		paths = ownedFields(append(append(fieldPath{}, path...), name), childNode, paths)
+	}
+	return paths
+}
+
+// managedBy returns an entry for an Update by manager owning paths, as
+// kubectl edit or another controller would leave.
+func managedBy(manager string, paths ...fieldPath) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: pangolincrd.GroupVersion.String(),
+		FieldsType: "FieldsV1",
+		FieldsV1:   toFieldsV1(paths),
+	}
+}
+
+func TestApply_CreatesResource(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	reconcileIngress(t, r)
+
+	name := r.Render(ingress, nil, "default", "")[0].Resource.Name
+	resource, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	assert.Equal(t, "default/myapp/app.example.com", resource.Spec.Name)
+	assert.Equal(t, "app", resource.Spec.HTTPConfig.Subdomain)
+	assert.Equal(t, "example.com", resource.Spec.HTTPConfig.DomainName)
+	require.Len(t, resource.Spec.Targets, 1)
+	assert.Equal(t, int32(8080), resource.Spec.Targets[0].Port)
+	assert.Equal(t, "ingress-uid", resource.Labels[controller.LabelIngressUID])
+	assert.Equal(t, ingress.UID, metav1.GetControllerOf(resource).UID)
+
+	require.Len(t, resource.ManagedFields, 1)
+	assert.Equal(t, controller.FieldManager, resource.ManagedFields[0].Manager)
+	assert.Equal(t, metav1.ManagedFieldsOperationApply, resource.ManagedFields[0].Operation)
+
+	assert.Equal(t, []string{"Normal Created Created PangolinResource " + name}, drainEvents(r))
+}
+
+func TestApply_UpdatesResourceAndSkipsNoOps(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	reconcileIngress(t, r)
+	drainEvents(r)
+	name := r.Render(ingress, nil, "default", "")[0].Resource.Name
+	created, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+
+	// Nothing changed: the apply does not write
+	reconcileIngress(t, r)
+	assert.Empty(t, drainEvents(r))
+	unchanged, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	assert.Equal(t, created.ResourceVersion, unchanged.ResourceVersion)
+
+	// A new backend port updates the resource
+	var live networkingv1.Ingress
+	require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(ingress), &live))
+	live.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Number = 9090
+	require.NoError(t, r.Update(context.Background(), &live))
+
+	reconcileIngress(t, r)
+	assert.Equal(t, []string{"Normal Updated Updated PangolinResource " + name}, drainEvents(r))
+	updated, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	assert.NotEqual(t, created.ResourceVersion, updated.ResourceVersion)
+	assert.Equal(t, int32(9090), updated.Spec.Targets[0].Port)
+}
+
+func TestLifecycle_LabelsChanged_AppliedToPangolinResource(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	reconcileIngress(t, r)
+	name := r.Render(ingress, nil, "default", "")[0].Resource.Name
+
+	// kubectl removes a PIC label and adds one of its own
+	resource, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	delete(resource.Labels, controller.LabelIngressName)
+	resource.Labels["team"] = "payments"
+	resource.ManagedFields = append(resource.ManagedFields, managedBy("kubectl-label", fieldPath{"metadata", "labels", "team"}))
+	require.NoError(t, r.Update(context.Background(), resource))
+	drainEvents(r)
+
+	reconcileIngress(t, r)
+	resource, err = getResource(t, r, "default", name)
+	require.NoError(t, err)
+	assert.Equal(t, "myapp", resource.Labels[controller.LabelIngressName], "the label is applied back")
+	assert.Equal(t, "payments", resource.Labels["team"], "labels of other actors are left untouched")
+	assert.Contains(t, drainEvents(r), "Normal Updated Updated PangolinResource "+name)
+}
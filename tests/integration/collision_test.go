@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/util"
+)
+
+func TestCollision_FallsBackToLongerHash(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+
+	// Stands in for a resource of another host whose short hash is the same,
+	// left by an earlier Ingress of the same name
+	taken := newRenamedResource(util.GenerateName("default", "myapp", "app.example.com"),
+		"other.example.com", "myapp", "earlier-uid")
+	fallback := util.GenerateLongName("default", "myapp", "app.example.com")
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress, taken)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.Len(t, exp.Hosts, 1)
+	assert.Equal(t, fallback, exp.Hosts[0].ResourceName)
+	require.Len(t, exp.Hosts[0].Conflicts, 1)
+	assert.Contains(t, exp.Hosts[0].Conflicts[0], `was created for host "other.example.com"`)
+
+	reconcileIngress(t, r)
+	events := drainEvents(r)
+	require.NotEmpty(t, events)
+	assert.Contains(t, events[0], "NameCollision")
+	assert.Contains(t, events, "Normal Created Created PangolinResource "+fallback)
+
+	created, err := getResource(t, r, "default", fallback)
+	require.NoError(t, err)
+	assert.Equal(t, "app.example.com", created.Annotations[controller.AnnotationHost])
+
+	unchanged, err := getResource(t, r, "default", taken.Name)
+	require.NoError(t, err)
+	assert.Equal(t, "other.example.com", unchanged.Annotations[controller.AnnotationHost])
+}
+
+func TestCollision_UnrecordedHostIsNotACollision(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+
+	// Created before PIC recorded the host
+	legacy := ownedBy(newLabeledResource(util.GenerateName("default", "myapp", "app.example.com"),
+		"default", "myapp", "ingress-uid"), ingress)
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress, legacy)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.Len(t, exp.Hosts, 1)
+	assert.Equal(t, legacy.Name, exp.Hosts[0].ResourceName)
+	assert.Empty(t, exp.Hosts[0].Conflicts)
+}
@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
+)
+
+func TestNamespaceDefaults_MergedBeneathIngressAnnotations(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "team-a",
+			Annotations: map[string]string{
+				controller.AnnotationSSO:         "true",
+				controller.AnnotationBlockAccess: "true",
+			},
+		},
+	}
+	tunnel := newTestTunnel("team-a-tunnel")
+	tunnel.Namespace = "pangolin-system"
+
+	ingress := newTestIngress("myapp", "team-a", "app.example.com")
+	ingress.Annotations = map[string]string{controller.AnnotationBlockAccess: "false"}
+
+	sso := false
+	policy := &piccrd.PangolinIngressPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "team-a"},
+		Spec: piccrd.PangolinIngressPolicySpec{
+			TunnelName: "team-a-tunnel",
+			SSO:        &sso,
+		},
+	}
+
+	grant := newTunnelGrant("pangolin-system", "shared", []string{"team-a"})
+	r := newFakeReconciler(t, ns, tunnel, grant, policy, ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+
+	// Namespace annotations override the policy, and the Ingress's own
+	// annotations override both
+	assert.Equal(t, []string{
+		"pangolin.ingress.k8s.io/sso=true (Namespace team-a annotation)",
+		"pangolin.ingress.k8s.io/tunnel-name=team-a-tunnel (PangolinIngressPolicy team-a/defaults)",
+	}, exp.Defaults)
+	assert.Len(t, ingress.Annotations, 1, "Explain must not modify the Ingress")
+
+	assert.Equal(t, "team-a-tunnel", exp.TunnelName)
+	assert.Equal(t, "pangolin-system", exp.TunnelNamespace)
+	assert.Equal(t, "namespace default from PangolinIngressPolicy team-a/defaults", exp.TunnelSource)
+
+	_, err = r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "myapp"},
+	})
+	require.NoError(t, err)
+	require.Len(t, exp.Hosts, 1)
+	resource, err := getResource(t, r, "team-a", exp.Hosts[0].ResourceName)
+	require.NoError(t, err)
+	assert.True(t, resource.Spec.HTTPConfig.SSO)
+	assert.False(t, resource.Spec.HTTPConfig.BlockAccess)
+}
+
+func TestNamespaceDefaults_NoneSet(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+
+	r := newFakeReconciler(t, ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Empty(t, exp.Defaults)
+}
+
+func TestNamespaceDefaults_TunnelBelowClassAndMapping(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "team-a",
+			Annotations: map[string]string{controller.AnnotationTunnelName: "team-a-tunnel"},
+		},
+	}
+	cfg := config.Default()
+	cfg.TunnelMapping = map[string]string{"eu": "tunnel-eu"}
+
+	tests := []struct {
+		name      string
+		className string
+		objs      []client.Object
+		want      string
+	}{
+		{name: "default class", className: "pangolin", want: "team-a-tunnel"},
+		{name: "tunnel mapping", className: "pangolin-eu", want: "tunnel-eu"},
+		{
+			name:      "class parameters",
+			className: "internal",
+			objs: []client.Object{
+				newTestIngressClass("internal", controller.ControllerName, "internal"),
+				&piccrd.PangolinIngressClassConfig{
+					ObjectMeta: metav1.ObjectMeta{Name: "internal"},
+					Spec:       piccrd.PangolinIngressClassConfigSpec{TunnelName: "class-tunnel"},
+				},
+			},
+			want: "class-tunnel",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingress := newTestIngress("myapp", "team-a", "app.example.com")
+			ingress.Spec.IngressClassName = &tt.className
+
+			r := newFakeReconcilerWithConfig(t, cfg, append(tt.objs, ns, ingress)...)
+
+			exp, err := r.Explain(context.Background(), ingress)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, exp.TunnelName)
+		})
+	}
+}
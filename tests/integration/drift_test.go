@@ -0,0 +1,223 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/metrics"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+var ssoField = fieldPath{"spec", "httpConfig", "sso"}
+
+// editAs updates the resource as manager would with kubectl edit: the
+// changed fields move from their current owners to an Update entry of manager.
+func editAs(t *testing.T, r *controller.IngressReconciler, resource *pangolincrd.PangolinResource, manager string, paths ...fieldPath) {
+	t.Helper()
+	resource.ManagedFields = append(disown(resource, paths...), managedBy(manager, paths...))
+	require.NoError(t, r.Update(context.Background(), resource))
+}
+
+// removeFields updates the resource after fields were deleted from it, as
+// with kubectl edit: the API server drops them from every manager's entry,
+// so no manager owns them any more.
+func removeFields(t *testing.T, r *controller.IngressReconciler, resource *pangolincrd.PangolinResource, paths ...fieldPath) {
+	t.Helper()
+	resource.ManagedFields = disown(resource, paths...)
+	require.NoError(t, r.Update(context.Background(), resource))
+}
+
+// disown returns the managedFields of the resource without the paths.
+func disown(resource *pangolincrd.PangolinResource, paths ...fieldPath) []metav1.ManagedFieldsEntry {
+	var entries []metav1.ManagedFieldsEntry
+	for _, entry := range resource.ManagedFields {
+		var kept []fieldPath
+		for _, owned := range parseFieldsV1(entry.FieldsV1) {
+			if !containsPath(paths, owned) {
+				kept = append(kept, owned)
+			}
+		}
+		if len(kept) > 0 {
+			entry.FieldsV1 = toFieldsV1(kept)
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// setBackendPort changes the backend port of the Ingress's first path.
+func setBackendPort(t *testing.T, r *controller.IngressReconciler, ingress *networkingv1.Ingress, port int32) {
+	t.Helper()
+	var live networkingv1.Ingress
+	require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(ingress), &live))
+	live.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Number = port
+	require.NoError(t, r.Update(context.Background(), &live))
+}
+
+func TestDrift_ManualEditReverted(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	reconcileIngress(t, r)
+	name := r.Render(ingress, nil, "default", "")[0].Resource.Name
+	resource, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	sso := resource.Spec.HTTPConfig.SSO
+	resource.Spec.HTTPConfig.SSO = !sso
+	editAs(t, r, resource, "kubectl-edit", ssoField)
+	drainEvents(r)
+	repairs := testutil.ToFloat64(metrics.DriftRepairsTotal.WithLabelValues("default"))
+
+	reconcileIngress(t, r)
+	resource, err = getResource(t, r, "default", name)
+	require.NoError(t, err)
+	assert.Equal(t, sso, resource.Spec.HTTPConfig.SSO, "the manual change is reverted")
+	events := drainEvents(r)
+	require.NotEmpty(t, events)
+	assert.Contains(t, events[0], "DriftDetected")
+	assert.Contains(t, events[0], "was changed by kubectl-edit: spec.httpConfig.sso")
+	assert.Equal(t, repairs+1, testutil.ToFloat64(metrics.DriftRepairsTotal.WithLabelValues("default")))
+
+	// The forced apply took the field back: the next reconcile is quiet
+	reconcileIngress(t, r)
+	assert.Empty(t, drainEvents(r))
+}
+
+func TestDrift_RemovedFieldReverted(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	reconcileIngress(t, r)
+	name := r.Render(ingress, nil, "default", "")[0].Resource.Name
+	resource, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	httpConfig := resource.Spec.HTTPConfig
+	require.NotNil(t, httpConfig)
+	resource.Spec.HTTPConfig = nil
+	removeFields(t, r, resource, fieldPath{"spec", "httpConfig"})
+	drainEvents(r)
+	repairs := testutil.ToFloat64(metrics.DriftRepairsTotal.WithLabelValues("default"))
+
+	reconcileIngress(t, r)
+	resource, err = getResource(t, r, "default", name)
+	require.NoError(t, err)
+	assert.Equal(t, httpConfig, resource.Spec.HTTPConfig, "the removed field is restored")
+	events := drainEvents(r)
+	require.NotEmpty(t, events)
+	assert.Contains(t, events[0], "DriftDetected")
+	assert.Contains(t, events[0], "PangolinResource "+name+" was changed: spec.httpConfig: <unset> -> ")
+	assert.Equal(t, repairs+1, testutil.ToFloat64(metrics.DriftRepairsTotal.WithLabelValues("default")))
+
+	reconcileIngress(t, r)
+	assert.Empty(t, drainEvents(r))
+}
+
+func TestDrift_AllowManualChangesKeepsRemovedField(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	ingress.Annotations = map[string]string{controller.AnnotationAllowManualChanges: "true"}
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	reconcileIngress(t, r)
+	name := r.Render(ingress, nil, "default", "")[0].Resource.Name
+	resource, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	resource.Spec.HTTPConfig = nil
+	removeFields(t, r, resource, fieldPath{"spec", "httpConfig"})
+	drainEvents(r)
+
+	for range 2 {
+		reconcileIngress(t, r)
+		resource, err = getResource(t, r, "default", name)
+		require.NoError(t, err)
+		assert.Nil(t, resource.Spec.HTTPConfig, "the removal is kept")
+		assert.Empty(t, drainEvents(r))
+	}
+}
+
+func TestDrift_IngressChangeWithStaleForeignManager(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	reconcileIngress(t, r)
+	name := r.Render(ingress, nil, "default", "")[0].Resource.Name
+
+	// Another manager once labeled the resource; it owns nothing PIC sets
+	resource, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	resource.Labels["team"] = "payments"
+	editAs(t, r, resource, "kubectl-label", fieldPath{"metadata", "labels", "team"})
+	drainEvents(r)
+
+	repairs := testutil.ToFloat64(metrics.DriftRepairsTotal.WithLabelValues("default"))
+
+	setBackendPort(t, r, ingress, 9090)
+	reconcileIngress(t, r)
+	resource, err = getResource(t, r, "default", name)
+	require.NoError(t, err)
+	assert.Equal(t, int32(9090), resource.Spec.Targets[0].Port)
+	assert.Equal(t, []string{"Normal Updated Updated PangolinResource " + name}, drainEvents(r),
+		"a change coming from the Ingress is not drift")
+	assert.Equal(t, repairs, testutil.ToFloat64(metrics.DriftRepairsTotal.WithLabelValues("default")))
+}
+
+func TestDrift_AllowManualChangesKeepsEditsAndAppliesIngress(t *testing.T) {
+	tests := []struct {
+		name       string
+		onIngress  bool
+		onResource bool
+	}{
+		{name: "annotation on the Ingress", onIngress: true},
+		{name: "annotation on the PangolinResource", onResource: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingress := newTestIngress("myapp", "default", "app.example.com")
+			ingress.UID = "ingress-uid"
+			if tt.onIngress {
+				ingress.Annotations = map[string]string{controller.AnnotationAllowManualChanges: "true"}
+			}
+			r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+			reconcileIngress(t, r)
+			name := r.Render(ingress, nil, "default", "")[0].Resource.Name
+			resource, err := getResource(t, r, "default", name)
+			require.NoError(t, err)
+			sso := resource.Spec.HTTPConfig.SSO
+			resource.Spec.HTTPConfig.SSO = !sso
+			paths := []fieldPath{ssoField}
+			if tt.onResource {
+				metav1.SetMetaDataAnnotation(&resource.ObjectMeta, controller.AnnotationAllowManualChanges, "true")
+				paths = append(paths, fieldPath{"metadata", "annotations", controller.AnnotationAllowManualChanges})
+			}
+			editAs(t, r, resource, "kubectl-edit", paths...)
+			drainEvents(r)
+
+			setBackendPort(t, r, ingress, 9090)
+			reconcileIngress(t, r)
+			resource, err = getResource(t, r, "default", name)
+			require.NoError(t, err)
+			assert.Equal(t, !sso, resource.Spec.HTTPConfig.SSO, "the manual change is kept")
+			assert.Equal(t, int32(9090), resource.Spec.Targets[0].Port, "the Ingress change is applied")
+			assert.Equal(t, []string{"Normal Updated Updated PangolinResource " + name}, drainEvents(r))
+
+			// Still kept once PIC's apply shares the field
+			reconcileIngress(t, r)
+			resource, err = getResource(t, r, "default", name)
+			require.NoError(t, err)
+			assert.Equal(t, !sso, resource.Spec.HTTPConfig.SSO)
+			assert.Empty(t, drainEvents(r))
+		})
+	}
+}
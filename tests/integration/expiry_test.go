@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/metrics"
+)
+
+func newExpiringIngress(annotations map[string]string) *networkingv1.Ingress {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	ingress.CreationTimestamp = metav1.NewTime(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC))
+	ingress.Annotations = annotations
+	return ingress
+}
+
+func TestExpiry_EarlierAnnotationApplies(t *testing.T) {
+	ingress := newExpiringIngress(map[string]string{
+		controller.AnnotationExpiresAfter: "2h",
+		controller.AnnotationExpiresAt:    "2026-10-18T12:00:00Z",
+	})
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+	r.Clock = func() time.Time { return time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC) }
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "expires at 2026-10-18T11:00:00Z", exp.Expiry)
+
+	result := reconcileIngress(t, r)
+	assert.Equal(t, time.Hour+time.Second, result.RequeueAfter, "requeued at the expiry")
+	assert.Equal(t, float64(time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC).Unix()),
+		testutil.ToFloat64(metrics.NextIngressExpiry))
+
+	resource, err := getResource(t, r, "default", r.Render(ingress, nil, "default", "")[0].Resource.Name)
+	require.NoError(t, err)
+	assert.True(t, resource.Spec.Enabled, "exposed until it expires")
+}
+
+func TestExpiry_DeleteAction(t *testing.T) {
+	ingress := newExpiringIngress(map[string]string{controller.AnnotationExpiresAfter: "1h"})
+	resource := ownedBy(newRenamedResource("pic-default-myapp-deadbeef", "app.example.com", "myapp", "ingress-uid"), ingress)
+	resource.Finalizers = []string{operatorFinalizer}
+
+	cfg := config.Default()
+	cfg.ExpiryAction = config.ExpiryActionDelete
+	r := newFakeReconcilerWithConfig(t, cfg, newTestTunnel("default"), ingress, resource)
+	r.Clock = func() time.Time { return time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC) }
+
+	reconcileIngress(t, r)
+
+	deleting, err := getResource(t, r, "default", resource.Name)
+	require.NoError(t, err)
+	assert.False(t, deleting.DeletionTimestamp.IsZero(), "the resource of the expired Ingress is deleted")
+	events := drainEvents(r)
+	assert.Contains(t, events, "Normal Expired Ingress expired at 2026-10-18T10:00:00Z")
+	assert.Contains(t, events, "Normal Deleted Deleted PangolinResource "+resource.Name+" (expired)")
+
+	// Neither reported nor deleted again while pangolin-operator finishes
+	reconcileIngress(t, r)
+	assert.Empty(t, drainEvents(r))
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "expired at 2026-10-18T10:00:00Z, PangolinResources are deleted", exp.Expiry)
+}
+
+func TestExpiry_DisableActionAndInvalidAnnotation(t *testing.T) {
+	ingress := newExpiringIngress(map[string]string{controller.AnnotationExpiresAt: "2026-10-18T09:30:00Z"})
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+	r.Clock = func() time.Time { return time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC) }
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "expired at 2026-10-18T09:30:00Z, PangolinResources are disabled", exp.Expiry)
+
+	ingress.Annotations[controller.AnnotationExpiresAt] = "tomorrow"
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Contains(t, exp.Expiry, "hidden: invalid expiry")
+}
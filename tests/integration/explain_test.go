@@ -0,0 +1,131 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
+)
+
+func newFakeReconciler(t testing.TB, objs ...client.Object) *controller.IngressReconciler {
+	return newFakeReconcilerWithConfig(t, config.Default(), objs...)
+}
+
+func newFakeReconcilerWithConfig(t testing.TB, cfg *config.Config, objs ...client.Object) *controller.IngressReconciler {
+	c, scheme := newFakeClient(t, objs...)
+	return controller.NewIngressReconciler(c, scheme, cfg, logr.Discard(), record.NewFakeRecorder(100))
+}
+
+// newFakeClient builds a fake client with the reconciler's indexes and
+// server-side apply of PangolinResources.
+func newFakeClient(t testing.TB, objs ...client.Object) (client.Client, *runtime.Scheme) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, pangolincrd.AddToScheme(scheme))
+	require.NoError(t, piccrd.AddToScheme(scheme))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithIndex(&pangolincrd.PangolinTunnel{}, controller.TunnelNameIndex, controller.IndexTunnelName).
+		WithIndex(&pangolincrd.PangolinResource{}, controller.OwnerUIDIndex, controller.IndexOwnerUID).
+		WithInterceptorFuncs(interceptor.Funcs{Patch: serverSideApply}).
+		Build()
+	return c, scheme
+}
+
+func TestExplain_ManagedIngress(t *testing.T) {
+	tunnel := newTestTunnel("default")
+	tunnel.Namespace = "pangolin-system"
+
+	ingress := newMultiHostIngress("myapp", "default", []string{"app.example.com", "*.example.com"})
+	ingress.UID = "ingress-uid"
+
+	orphan := &pangolincrd.PangolinResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pic-default-myapp-deadbeef",
+			Namespace: "default",
+			Labels:    map[string]string{controller.LabelIngressUID: "ingress-uid"},
+		},
+	}
+
+	grant := newTunnelGrant("pangolin-system", "shared", []string{"default"})
+	r := newFakeReconciler(t, tunnel, grant, ingress, orphan)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+
+	assert.True(t, exp.Managed)
+	assert.Equal(t, "default", exp.TunnelName)
+	assert.Equal(t, "pangolin-system", exp.TunnelNamespace)
+	assert.NoError(t, exp.TunnelError)
+
+	require.Len(t, exp.Hosts, 2)
+	assert.Equal(t, "*.example.com", exp.Hosts[0].Host)
+	assert.Error(t, exp.Hosts[0].Err)
+	assert.Equal(t, "app.example.com", exp.Hosts[1].Host)
+	assert.Equal(t, "app", exp.Hosts[1].Subdomain)
+	assert.Equal(t, "example.com", exp.Hosts[1].Domain)
+	assert.Nil(t, exp.Hosts[1].Resource)
+
+	assert.Equal(t, []string{"pic-default-myapp-deadbeef"}, exp.Orphans)
+}
+
+func TestExplain_UnmanagedIngress(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	className := "nginx"
+	ingress.Spec.IngressClassName = &className
+
+	r := newFakeReconciler(t, ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+
+	assert.False(t, exp.Managed)
+	assert.Contains(t, exp.ManagedReason, "nginx")
+	assert.Empty(t, exp.Hosts)
+}
+
+func TestExplain_PrintsReconcilePlan(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+
+	// Found by its owner reference alone, like the controller finds it
+	unlabeled := ownedBy(&pangolincrd.PangolinResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "pic-default-myapp-unlabeled", Namespace: "default"},
+	}, ingress)
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress, unlabeled)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "apply the PangolinResources of the hosts below", exp.Action)
+	assert.Equal(t, []string{unlabeled.Name}, exp.Orphans)
+	require.Len(t, exp.Hosts, 1)
+
+	name := exp.Hosts[0].ResourceName
+
+	reconcileIngress(t, r)
+	assert.Contains(t, drainEvents(r), "Normal Deleted Deleted PangolinResource "+unlabeled.Name+" (host removed)")
+	_, err = getResource(t, r, "default", name)
+	assert.NoError(t, err, "the planned resource is created")
+
+	// Unmanaged: every resource the Ingress controls is deleted
+	ingress.Annotations = map[string]string{controller.AnnotationEnabled: "false"}
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "delete every PangolinResource, the Ingress is not managed", exp.Action)
+	assert.Equal(t, []string{name}, exp.Orphans)
+}
@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+)
+
+// operatorFinalizer stands in for pangolin-operator's own finalizer, which
+// keeps a PangolinResource until the Pangolin side is cleaned up.
+const operatorFinalizer = "tunnel.pangolin.io/finalizer"
+
+func newDeletedIngress(deletedAgo time.Duration) *networkingv1.Ingress {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	ingress.Finalizers = []string{controller.FinalizerCleanup}
+	deleted := metav1.NewTime(time.Now().Add(-deletedAgo))
+	ingress.DeletionTimestamp = &deleted
+	return ingress
+}
+
+func reconcileIngress(t *testing.T, r *controller.IngressReconciler) ctrl.Result {
+	t.Helper()
+	result, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "default", Name: "myapp"},
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func ingressExists(t *testing.T, r *controller.IngressReconciler) bool {
+	t.Helper()
+	var ingress networkingv1.Ingress
+	err := r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "myapp"}, &ingress)
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	require.NoError(t, err)
+	return true
+}
+
+func TestFinalizer_WaitsForPangolinResources(t *testing.T) {
+	ingress := newDeletedIngress(time.Minute)
+	resource := newLabeledResource("pic-default-myapp-deadbeef", "default", "myapp", "ingress-uid")
+	resource.Finalizers = []string{operatorFinalizer}
+
+	r := newFakeReconciler(t, ingress, resource)
+
+	result := reconcileIngress(t, r)
+	assert.NotZero(t, result.RequeueAfter)
+	assert.True(t, ingressExists(t, r), "the Ingress is held while the resource exists")
+
+	deleting, err := getResource(t, r, "default", resource.Name)
+	require.NoError(t, err)
+	assert.False(t, deleting.DeletionTimestamp.IsZero(), "the resource is being deleted")
+
+	recorder := r.Recorder.(*record.FakeRecorder)
+	assert.Contains(t, <-recorder.Events, "Deleted")
+	assert.Contains(t, <-recorder.Events, "WaitingForCleanup")
+
+	// Polling does not report the wait again
+	result = reconcileIngress(t, r)
+	assert.NotZero(t, result.RequeueAfter)
+	assert.Empty(t, drainEvents(r))
+
+	// pangolin-operator finishes
+	deleting.Finalizers = nil
+	require.NoError(t, r.Update(context.Background(), deleting))
+
+	reconcileIngress(t, r)
+	assert.False(t, ingressExists(t, r), "the Ingress is released")
+}
+
+func TestFinalizer_TimeoutAndForceRelease(t *testing.T) {
+	resource := newLabeledResource("pic-default-myapp-deadbeef", "default", "myapp", "ingress-uid")
+	resource.Finalizers = []string{operatorFinalizer}
+
+	// Past the timeout on the reconciler's clock the Ingress is released
+	// with a warning
+	r := newFakeReconciler(t, newDeletedIngress(time.Minute), resource.DeepCopy())
+	reconcileIngress(t, r)
+	assert.True(t, ingressExists(t, r))
+	r.Clock = func() time.Time { return time.Now().Add(time.Hour) }
+	drainEvents(r)
+	reconcileIngress(t, r)
+	assert.False(t, ingressExists(t, r))
+	recorder := r.Recorder.(*record.FakeRecorder)
+	assert.Contains(t, <-recorder.Events, "CleanupTimedOut")
+
+	// A zero timeout waits forever
+	cfg := config.Default()
+	cfg.FinalizerTimeout = 0
+	r = newFakeReconcilerWithConfig(t, cfg, newDeletedIngress(24*time.Hour), resource.DeepCopy())
+	reconcileIngress(t, r)
+	assert.True(t, ingressExists(t, r))
+
+	// The force-release annotation skips waiting
+	forced := newDeletedIngress(time.Minute)
+	forced.Annotations = map[string]string{controller.AnnotationForceRelease: "true"}
+	r = newFakeReconciler(t, forced, resource.DeepCopy())
+	reconcileIngress(t, r)
+	assert.False(t, ingressExists(t, r))
+	recorder = r.Recorder.(*record.FakeRecorder)
+	assert.Contains(t, <-recorder.Events, "CleanupForced")
+}
+
+func TestFinalizer_AddedOnlyWhenEnabled(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.Finalizers = []string{controller.FinalizerCleanup}
+
+	// Disabled: a finalizer left from an earlier setting is removed
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress.DeepCopy())
+	_, _ = r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "default", Name: "myapp"},
+	})
+	var got networkingv1.Ingress
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "myapp"}, &got))
+	assert.Empty(t, got.Finalizers)
+
+	// Enabled: managed Ingresses get it
+	cfg := config.Default()
+	cfg.IngressFinalizer = true
+	ingress.Finalizers = nil
+	r = newFakeReconcilerWithConfig(t, cfg, newTestTunnel("default"), ingress.DeepCopy())
+	_, _ = r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "default", Name: "myapp"},
+	})
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "myapp"}, &got))
+	assert.Equal(t, []string{controller.FinalizerCleanup}, got.Finalizers)
+}
@@ -0,0 +1,289 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
+)
+
+func newTestIngressClass(name, controllerName, configName string) *networkingv1.IngressClass {
+	class := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       networkingv1.IngressClassSpec{Controller: controllerName},
+	}
+	if configName != "" {
+		apiGroup := piccrd.GroupName
+		scope := networkingv1.IngressClassParametersReferenceScopeCluster
+		class.Spec.Parameters = &networkingv1.IngressClassParametersReference{
+			APIGroup: &apiGroup,
+			Kind:     piccrd.KindPangolinIngressClassConfig,
+			Name:     configName,
+			Scope:    &scope,
+		}
+	}
+	return class
+}
+
+func TestIngressClass_ConfigAppliedToAnyClassName(t *testing.T) {
+	sso := true
+	classConfig := &piccrd.PangolinIngressClassConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "internal"},
+		Spec: piccrd.PangolinIngressClassConfigSpec{
+			TunnelName:     "main",
+			BackendScheme:  "https",
+			SSO:            &sso,
+			AllowedDomains: []string{"example.com"},
+		},
+	}
+	class := newTestIngressClass("internal", controller.ControllerName, "internal")
+
+	tunnel := newTestTunnel("main")
+	tunnel.Namespace = "pangolin-system"
+
+	ingress := newMultiHostIngress("myapp", "default", []string{"app.example.com", "app.other.org"})
+	className := "internal"
+	ingress.Spec.IngressClassName = &className
+
+	r := newFakeReconciler(t, classConfig, class, tunnel, ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.NoError(t, exp.IngressClassError)
+
+	assert.True(t, exp.Managed)
+	assert.Equal(t, "main", exp.TunnelName)
+	assert.Contains(t, exp.TunnelSource, piccrd.KindPangolinIngressClassConfig)
+
+	rendered := r.Render(ingress, exp.IngressClass, exp.TunnelName, exp.TunnelNamespace)
+	require.Len(t, rendered, 2)
+
+	// Hosts are sorted: app.example.com, app.other.org
+	require.NoError(t, rendered[0].Err)
+	spec := rendered[0].Resource.Spec
+	assert.True(t, spec.HTTPConfig.SSO)
+	assert.False(t, spec.HTTPConfig.BlockAccess)
+	assert.Equal(t, "https", spec.Targets[0].Method)
+
+	assert.Error(t, rendered[1].Err)
+	assert.Nil(t, rendered[1].Resource)
+}
+
+func TestIngressClass_AnnotationOverridesClassDefaults(t *testing.T) {
+	sso := true
+	classConfig := &piccrd.PangolinIngressClassConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "internal"},
+		Spec:       piccrd.PangolinIngressClassConfigSpec{TunnelName: "main", SSO: &sso},
+	}
+	class := newTestIngressClass("internal", controller.ControllerName, "internal")
+
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	className := "internal"
+	ingress.Spec.IngressClassName = &className
+	ingress.Annotations = map[string]string{
+		controller.AnnotationSSO:        "false",
+		controller.AnnotationTunnelName: "other",
+	}
+
+	r := newFakeReconciler(t, classConfig, class, ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.NoError(t, exp.IngressClassError)
+	settings := exp.IngressClass
+
+	tunnelName, err := r.ResolveTunnel(ingress, settings)
+	require.NoError(t, err)
+	assert.Equal(t, "other", tunnelName)
+
+	rendered := r.Render(ingress, settings, tunnelName, "pangolin-system")
+	require.Len(t, rendered, 1)
+	require.NoError(t, rendered[0].Err)
+	assert.False(t, rendered[0].Resource.Spec.HTTPConfig.SSO)
+}
+
+func TestIngressClass_ForeignControllerNotManaged(t *testing.T) {
+	class := newTestIngressClass("pangolin-eu", "k8s.io/ingress-nginx", "")
+
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	className := "pangolin-eu"
+	ingress.Spec.IngressClassName = &className
+
+	r := newFakeReconciler(t, class, ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.NoError(t, exp.IngressClassError)
+	settings := exp.IngressClass
+	assert.False(t, r.IsManaged(ingress, settings))
+
+	// Without the IngressClass object the name convention still applies
+	assert.True(t, r.IsManaged(ingress, nil))
+}
+
+func TestIngressClass_MissingConfigIsAnError(t *testing.T) {
+	class := newTestIngressClass("internal", controller.ControllerName, "missing")
+
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	className := "internal"
+	ingress.Spec.IngressClassName = &className
+
+	r := newFakeReconciler(t, class, ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.False(t, exp.Managed)
+	assert.Error(t, exp.IngressClassError)
+}
+
+// withNamespacedConfig points the class parameters at a
+// PangolinNamespacedIngressClassConfig in namespace.
+func withNamespacedConfig(class *networkingv1.IngressClass, namespace string) *networkingv1.IngressClass {
+	scope := networkingv1.IngressClassParametersReferenceScopeNamespace
+	class.Spec.Parameters.Kind = piccrd.KindPangolinNamespacedIngressClassConfig
+	class.Spec.Parameters.Scope = &scope
+	class.Spec.Parameters.Namespace = &namespace
+	return class
+}
+
+func TestIngressClass_NamespacedConfig(t *testing.T) {
+	classConfig := &piccrd.PangolinNamespacedIngressClassConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "internal", Namespace: "team-a"},
+		Spec:       piccrd.PangolinIngressClassConfigSpec{TunnelName: "team-a-tunnel"},
+	}
+	class := withNamespacedConfig(newTestIngressClass("internal", controller.ControllerName, "internal"), "team-a")
+
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	className := "internal"
+	ingress.Spec.IngressClassName = &className
+
+	r := newFakeReconciler(t, classConfig, class, newTestTunnel("team-a-tunnel"), ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.NoError(t, exp.IngressClassError)
+	assert.True(t, exp.Managed)
+	assert.Equal(t, "team-a-tunnel", exp.TunnelName)
+	assert.Equal(t, "PangolinNamespacedIngressClassConfig team-a/internal", exp.TunnelSource)
+}
+
+func TestIngressClass_MisreferencedNamespacedConfig(t *testing.T) {
+	classConfig := &piccrd.PangolinNamespacedIngressClassConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "internal", Namespace: "team-a"},
+	}
+	tests := []struct {
+		name    string
+		class   *networkingv1.IngressClass
+		message string
+	}{
+		{
+			name:    "in another namespace",
+			class:   withNamespacedConfig(newTestIngressClass("internal", controller.ControllerName, "internal"), "team-b"),
+			message: "failed to get PangolinNamespacedIngressClassConfig team-b/internal",
+		},
+		{
+			name:    "without a namespace",
+			class:   withNamespacedConfig(newTestIngressClass("internal", controller.ControllerName, "internal"), ""),
+			message: `parameters with scope "Namespace" must set a namespace`,
+		},
+		{
+			name: "cluster-scoped kind with scope Namespace",
+			class: func() *networkingv1.IngressClass {
+				class := withNamespacedConfig(newTestIngressClass("internal", controller.ControllerName, "internal"), "team-a")
+				class.Spec.Parameters.Kind = piccrd.KindPangolinIngressClassConfig
+				return class
+			}(),
+			message: `PangolinIngressClassConfig is referenced with scope "Namespace"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingress := newTestIngress("myapp", "default", "app.example.com")
+			className := "internal"
+			ingress.Spec.IngressClassName = &className
+
+			r := newFakeReconciler(t, classConfig, tt.class, ingress)
+
+			exp, err := r.Explain(context.Background(), ingress)
+			require.NoError(t, err)
+			require.Error(t, exp.IngressClassError)
+			assert.Contains(t, exp.IngressClassError.Error(), tt.message)
+		})
+	}
+}
+
+func TestIngressClass_DefaultClassManagesClasslessIngress(t *testing.T) {
+	class := newTestIngressClass("internal", controller.ControllerName, "")
+	class.Annotations = map[string]string{networkingv1.AnnotationIsDefaultIngressClass: "true"}
+
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.Spec.IngressClassName = nil
+
+	r := newFakeReconciler(t, class, ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.NoError(t, exp.IngressClassError)
+	settings := exp.IngressClass
+	assert.Equal(t, "internal", settings.Name)
+	assert.True(t, r.IsManaged(ingress, settings))
+
+	tunnelName, err := r.ResolveTunnel(ingress, settings)
+	require.NoError(t, err)
+	assert.Equal(t, "default", tunnelName)
+}
+
+func TestIngressClass_ForeignDefaultClassNotManaged(t *testing.T) {
+	class := newTestIngressClass("nginx", "k8s.io/ingress-nginx", "")
+	class.Annotations = map[string]string{networkingv1.AnnotationIsDefaultIngressClass: "true"}
+
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.Spec.IngressClassName = nil
+
+	r := newFakeReconciler(t, class, ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.NoError(t, exp.IngressClassError)
+	settings := exp.IngressClass
+	assert.False(t, r.IsManaged(ingress, settings))
+
+	// Without any default class a class-less Ingress is not managed
+	r = newFakeReconciler(t, ingress)
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.NoError(t, exp.IngressClassError)
+	settings = exp.IngressClass
+	assert.False(t, r.IsManaged(ingress, settings))
+}
+
+func TestIngressClass_LegacyAnnotation(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.Spec.IngressClassName = nil
+	ingress.Annotations = map[string]string{controller.AnnotationIngressClass: "pangolin-eu"}
+
+	r := newFakeReconciler(t, ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.NoError(t, exp.IngressClassError)
+	settings := exp.IngressClass
+	assert.Equal(t, "pangolin-eu", settings.Name)
+	assert.True(t, r.IsManaged(ingress, settings))
+	assert.True(t, r.IsManaged(ingress, nil))
+
+	tunnelName, err := r.ResolveTunnel(ingress, settings)
+	require.NoError(t, err)
+	assert.Equal(t, "eu", tunnelName)
+
+	// spec.ingressClassName takes precedence over the annotation
+	nginx := "nginx"
+	ingress.Spec.IngressClassName = &nginx
+	assert.False(t, r.IsManaged(ingress, nil))
+}
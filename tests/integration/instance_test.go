@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+func withInstance(resource *pangolincrd.PangolinResource, id string) *pangolincrd.PangolinResource {
+	resource.Labels[controller.LabelInstance] = id
+	return resource
+}
+
+func newInstanceConfig(id string) *config.Config {
+	cfg := config.Default()
+	cfg.InstanceID = id
+	return cfg
+}
+
+func TestInstance_LabelsCreatedResources(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	r := newFakeReconcilerWithConfig(t, newInstanceConfig("prod"), ingress)
+
+	rendered := r.Render(ingress, nil, "default", "default")
+	require.Len(t, rendered, 1)
+	require.NoError(t, rendered[0].Err)
+	assert.Equal(t, "prod", rendered[0].Resource.Labels[controller.LabelInstance])
+
+	r = newFakeReconciler(t, ingress)
+	rendered = r.Render(ingress, nil, "default", "default")
+	require.Len(t, rendered, 1)
+	assert.NotContains(t, rendered[0].Resource.Labels, controller.LabelInstance)
+}
+
+func TestInstance_RefusesResourceOfOtherInstance(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+
+	probe := newFakeReconciler(t, ingress)
+	name := probe.Render(ingress, nil, "default", "default")[0].Resource.Name
+	staging := withInstance(ownedBy(newRenamedResource(name, "app.example.com", "myapp", "ingress-uid"), ingress), "staging")
+
+	r := newFakeReconcilerWithConfig(t, newInstanceConfig("prod"), newTestTunnel("default"), ingress, staging)
+
+	reconcileIngress(t, r)
+	assert.Contains(t, drainEvents(r),
+		`Warning OwnedByOtherInstance PangolinResource `+name+` was created by PIC instance "staging"; leaving it unchanged`)
+	unchanged, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	assert.Equal(t, staging.Spec, unchanged.Spec, "the apply is skipped")
+	assert.Equal(t, "staging", unchanged.Labels[controller.LabelInstance])
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.Len(t, exp.Hosts, 1)
+	assert.Equal(t, []string{`default/` + name + ` was created by PIC instance "staging" and is left unchanged`},
+		exp.Hosts[0].Conflicts)
+}
+
+func TestInstance_CleanupSkipsResourcesOfOtherInstance(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	ingress.Annotations = map[string]string{controller.AnnotationEnabled: "false"}
+
+	own := withInstance(ownedBy(newRenamedResource("pic-default-myapp-own", "app.example.com", "myapp", "ingress-uid"), ingress), "prod")
+	legacy := ownedBy(newRenamedResource("pic-default-myapp-legacy", "old.example.com", "myapp", "ingress-uid"), ingress)
+	staging := withInstance(ownedBy(newRenamedResource("pic-default-myapp-staging", "app.example.com", "myapp", "ingress-uid"), ingress), "staging")
+
+	r := newFakeReconcilerWithConfig(t, newInstanceConfig("prod"), newTestTunnel("default"), ingress, own, legacy, staging)
+	reconcileIngress(t, r)
+
+	_, err := getResource(t, r, "default", own.Name)
+	assert.Error(t, err, "the instance's own resource is deleted")
+	_, err = getResource(t, r, "default", legacy.Name)
+	assert.Error(t, err, "unlabeled resources belong to any instance")
+	_, err = getResource(t, r, "default", staging.Name)
+	assert.NoError(t, err, "the other instance's resource is left alone")
+}
+
+func TestInstance_SweepSkipsResourcesOfOtherInstance(t *testing.T) {
+	orphan := withInstance(newLabeledResource("pic-default-gone-1", "default", "gone", "gone-uid"), "staging")
+
+	r := newFakeReconcilerWithConfig(t, newInstanceConfig("prod"), orphan)
+	require.NoError(t, r.SweepOrphans(context.Background()))
+
+	_, err := getResource(t, r, "default", orphan.Name)
+	assert.NoError(t, err)
+}
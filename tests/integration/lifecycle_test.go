@@ -107,7 +107,8 @@ func newTestIngress(name, namespace, host string) *networkingv1.Ingress {
 func newTestTunnel(name string) *pangolincrd.PangolinTunnel {
 	return &pangolincrd.PangolinTunnel{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
+			Name:      name,
+			Namespace: "default",
 		},
 		Spec: pangolincrd.PangolinTunnelSpec{
 			SiteID: "test-site",
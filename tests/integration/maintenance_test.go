@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+)
+
+func TestMaintenance_PausedIngressIsLeftAlone(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	ingress.Annotations = map[string]string{controller.AnnotationPaused: "true"}
+
+	// Would otherwise be deleted as the resource of a removed host
+	stale := ownedBy(newRenamedResource("pic-default-myapp-stale", "gone.example.com", "myapp", "ingress-uid"), ingress)
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress, stale)
+
+	result := reconcileIngress(t, r)
+	assert.Zero(t, result)
+
+	_, err := getResource(t, r, "default", stale.Name)
+	assert.NoError(t, err)
+	recorder := r.Recorder.(*record.FakeRecorder)
+	assert.Contains(t, <-recorder.Events, "Paused")
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.True(t, exp.Paused)
+}
+
+func TestMaintenance_PausedEventOnTransitions(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.Annotations = map[string]string{controller.AnnotationPaused: "true"}
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	reconcileIngress(t, r)
+	assert.Equal(t, []string{"Normal Paused Annotation " + controller.AnnotationPaused +
+		" is set, PangolinResources are left unchanged"}, drainEvents(r))
+	reconcileIngress(t, r)
+	assert.Empty(t, drainEvents(r), "a still paused Ingress records no event")
+
+	// Resuming applies the resource; pausing again is reported again
+	require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(ingress), ingress))
+	ingress.Annotations = nil
+	require.NoError(t, r.Update(context.Background(), ingress))
+	reconcileIngress(t, r)
+	assert.NotContains(t, strings.Join(drainEvents(r), "\n"), "Paused")
+
+	ingress.Annotations = map[string]string{controller.AnnotationPaused: "true"}
+	require.NoError(t, r.Update(context.Background(), ingress))
+	reconcileIngress(t, r)
+	assert.Contains(t, strings.Join(drainEvents(r), "\n"), "Normal Paused")
+}
+
+func TestMaintenance_PausedUnmanagedIngressIsNotReported(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	nginx := "nginx"
+	ingress.Spec.IngressClassName = &nginx
+	ingress.Annotations = map[string]string{controller.AnnotationPaused: "true"}
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	reconcileIngress(t, r)
+	assert.Empty(t, drainEvents(r))
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.False(t, exp.Paused)
+	assert.False(t, exp.Managed)
+}
+
+func TestMaintenance_DisabledIngress(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.Annotations = map[string]string{controller.AnnotationDisabled: "true"}
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	rendered := r.Render(ingress, nil, "default", "default")
+	require.Len(t, rendered, 1)
+	require.NoError(t, rendered[0].Err)
+	assert.False(t, rendered[0].Resource.Spec.Enabled)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Contains(t, exp.Disabled, "on the Ingress")
+
+	ingress.Annotations = nil
+	rendered = r.Render(ingress, nil, "default", "default")
+	require.Len(t, rendered, 1)
+	assert.True(t, rendered[0].Resource.Spec.Enabled)
+}
+
+func TestMaintenance_DisabledTunnel(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	tunnel := newNamespacedTunnel("edge", "default")
+	tunnel.Annotations = map[string]string{controller.AnnotationDisabled: "true"}
+
+	r := newFakeReconciler(t, tunnel, newTunnelGrant("edge", "shared", []string{"default"}), ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Contains(t, exp.Disabled, "on tunnel edge/default")
+
+	tunnel.Annotations = nil
+	require.NoError(t, r.Update(context.Background(), tunnel))
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Empty(t, exp.Disabled)
+}
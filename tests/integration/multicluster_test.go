@@ -0,0 +1,157 @@
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// fakeCluster is a management cluster backed by a fake client.
+type fakeCluster struct {
+	cluster.Cluster
+	client client.Client
+}
+
+func (c fakeCluster) GetClient() client.Client { return c.client }
+
+// newMultiClusterReconciler returns a reconciler for cluster edge-1, with
+// local objects and objects in the management cluster.
+func newMultiClusterReconciler(t *testing.T, local, management []client.Object) *controller.IngressReconciler {
+	r := newFakeReconciler(t, local...)
+	c, _ := newFakeClient(t, management...)
+	r.Management = &controller.ManagementCluster{Cluster: fakeCluster{client: c}, ClusterName: "edge-1"}
+	return r
+}
+
+// newClusterResource returns a PangolinResource created in multi-cluster
+// mode for the "myapp" Ingress of cluster.
+func newClusterResource(name, cluster, ingressUID string) *pangolincrd.PangolinResource {
+	resource := newLabeledResource(name, "default", "myapp", ingressUID)
+	resource.Labels[controller.LabelCluster] = cluster
+	return resource
+}
+
+func getManagementResource(t *testing.T, r *controller.IngressReconciler, name string) error {
+	t.Helper()
+	var resource pangolincrd.PangolinResource
+	return r.Management.GetClient().Get(context.Background(),
+		types.NamespacedName{Namespace: "default", Name: name}, &resource)
+}
+
+func TestMultiCluster_RendersClusterQualifiedResources(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	r := newMultiClusterReconciler(t, []client.Object{ingress}, nil)
+
+	rendered := r.Render(ingress, nil, "default", "default")
+	require.Len(t, rendered, 1)
+	require.NoError(t, rendered[0].Err)
+	resource := rendered[0].Resource
+
+	assert.True(t, strings.HasPrefix(resource.Name, "pic-edge-1-default-myapp-"), resource.Name)
+	assert.Equal(t, "edge-1", resource.Labels[controller.LabelCluster])
+	assert.Equal(t, "edge-1/default/myapp/app.example.com", resource.Spec.Name)
+	assert.Empty(t, resource.OwnerReferences)
+}
+
+func TestMultiCluster_TunnelFromManagementCluster(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	tunnel := newNamespacedTunnel("pangolin-system", "default")
+	grant := newTunnelGrant("pangolin-system", "shared", []string{"default"})
+	r := newMultiClusterReconciler(t, []client.Object{ingress, grant.DeepCopy()}, []client.Object{tunnel})
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Error(t, exp.TunnelError, "grants are read next to the tunnels, not from the local cluster")
+
+	require.NoError(t, r.Management.GetClient().Create(context.Background(), grant))
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.NoError(t, exp.TunnelError)
+	assert.Equal(t, "pangolin-system", exp.TunnelNamespace)
+
+	reconcileIngress(t, r)
+
+	var resources pangolincrd.PangolinResourceList
+	require.NoError(t, r.Management.GetClient().List(context.Background(), &resources))
+	require.Len(t, resources.Items, 1)
+	resource := resources.Items[0]
+	assert.True(t, strings.HasPrefix(resource.Name, "pic-edge-1-default-myapp-"), resource.Name)
+	assert.Equal(t, "edge-1", resource.Labels[controller.LabelCluster])
+	assert.Equal(t, pangolincrd.TunnelRef{Name: "default", Namespace: "pangolin-system"}, resource.Spec.TunnelRef)
+	assert.Empty(t, resource.OwnerReferences)
+	_, err = getResource(t, r, "default", resource.Name)
+	assert.Error(t, err, "nothing is written to the local cluster")
+
+	var live networkingv1.Ingress
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "myapp"}, &live))
+	assert.Contains(t, live.Finalizers, controller.FinalizerCleanup, "owner references cannot cross clusters")
+}
+
+func TestMultiCluster_FinalizerDeletesResourcesOfThisCluster(t *testing.T) {
+	ingress := newDeletedIngress(time.Minute)
+	own := newClusterResource("pic-edge-1-default-myapp-deadbeef", "edge-1", "ingress-uid")
+	other := newClusterResource("pic-edge-2-default-myapp-deadbeef", "edge-2", "ingress-uid")
+	r := newMultiClusterReconciler(t, []client.Object{ingress}, []client.Object{own, other})
+
+	reconcileIngress(t, r)
+	assert.Error(t, getManagementResource(t, r, own.Name))
+	assert.NoError(t, getManagementResource(t, r, other.Name), "another cluster's resource is left alone")
+
+	reconcileIngress(t, r)
+	assert.False(t, ingressExists(t, r), "the Ingress is released once its resources are gone")
+}
+
+func TestMultiCluster_SweepDeletesOrphansOfThisCluster(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+
+	kept := newClusterResource("pic-edge-1-default-myapp-deadbeef", "edge-1", "ingress-uid")
+	orphan := newClusterResource("pic-edge-1-default-myapp-gone", "edge-1", "gone-uid")
+	other := newClusterResource("pic-edge-2-default-myapp-gone", "edge-2", "gone-uid")
+	r := newMultiClusterReconciler(t, []client.Object{ingress}, []client.Object{kept, orphan, other})
+
+	require.NoError(t, r.SweepOrphans(context.Background()))
+
+	assert.NoError(t, getManagementResource(t, r, kept.Name), "the label makes the Ingress its controller")
+	assert.Error(t, getManagementResource(t, r, orphan.Name))
+	assert.NoError(t, getManagementResource(t, r, other.Name), "the other cluster's Ingresses are not visible here")
+}
+
+func TestMultiCluster_MissingNamespaceIsReported(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	r := newFakeReconciler(t, ingress)
+
+	// The API server rejects writes to a namespace that does not exist
+	management, _ := newFakeClient(t, newTestTunnel("default"))
+	c := interceptor.NewClient(management.(client.WithWatch), interceptor.Funcs{
+		Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+			return apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "default")
+		},
+	})
+	r.Management = &controller.ManagementCluster{Cluster: fakeCluster{client: c}, ClusterName: "edge-1"}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "default", Name: "myapp"},
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "namespace default does not exist in the management cluster")
+
+	events := strings.Join(drainEvents(r), "\n")
+	assert.Contains(t, events, "Warning ManagementNamespaceMissing")
+}
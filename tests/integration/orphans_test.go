@@ -0,0 +1,161 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+func newLabeledResource(name, namespace, ingressName, ingressUID string) *pangolincrd.PangolinResource {
+	return &pangolincrd.PangolinResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				controller.LabelIngressUID:       ingressUID,
+				controller.LabelIngressName:      ingressName,
+				controller.LabelIngressNamespace: namespace,
+			},
+		},
+	}
+}
+
+func ownedBy(resource *pangolincrd.PangolinResource, ingress *networkingv1.Ingress) *pangolincrd.PangolinResource {
+	controllerRef := true
+	resource.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "networking.k8s.io/v1",
+		Kind:       "Ingress",
+		Name:       ingress.Name,
+		UID:        ingress.UID,
+		Controller: &controllerRef,
+	}}
+	return resource
+}
+
+func getResource(t *testing.T, r *controller.IngressReconciler, namespace, name string) (*pangolincrd.PangolinResource, error) {
+	t.Helper()
+	var resource pangolincrd.PangolinResource
+	err := r.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, &resource)
+	return &resource, err
+}
+
+func TestSweepOrphans_DeletesAndRepairs(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "live-uid"
+
+	owned := ownedBy(newLabeledResource("pic-default-myapp-owned", "default", "myapp", "live-uid"), ingress)
+	restored := newLabeledResource("pic-default-myapp-restored", "default", "myapp", "live-uid")
+	orphan := newLabeledResource("pic-default-gone-orphan", "default", "gone", "gone-uid")
+	foreign := &pangolincrd.PangolinResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "hand-made", Namespace: "default"},
+	}
+
+	r := newFakeReconciler(t, ingress, owned, restored, orphan, foreign)
+	require.NoError(t, r.SweepOrphans(context.Background()))
+
+	_, err := getResource(t, r, "default", orphan.Name)
+	assert.True(t, apierrors.IsNotFound(err), "the Ingress of the orphan is gone")
+
+	resource, err := getResource(t, r, "default", restored.Name)
+	require.NoError(t, err)
+	ref := metav1.GetControllerOf(resource)
+	require.NotNil(t, ref, "a resource whose Ingress exists gets its owner reference back")
+	assert.Equal(t, ingress.UID, ref.UID)
+
+	_, err = getResource(t, r, "default", owned.Name)
+	assert.NoError(t, err)
+	_, err = getResource(t, r, "default", foreign.Name)
+	assert.NoError(t, err, "resources without PIC labels are left alone")
+}
+
+func TestSweepOrphans_AdoptsIntoRecreatedIngress(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "new-uid"
+
+	stale := newLabeledResource("pic-default-myapp-stale", "default", "myapp", "old-uid")
+	unrelated := newLabeledResource("pic-default-other-stale", "default", "other", "other-uid")
+
+	// With the delete policy the stale resource goes
+	r := newFakeReconciler(t, ingress, stale.DeepCopy(), unrelated.DeepCopy())
+	require.NoError(t, r.SweepOrphans(context.Background()))
+	_, err := getResource(t, r, "default", stale.Name)
+	assert.True(t, apierrors.IsNotFound(err))
+
+	// With the adopt policy it is handed to the recreated Ingress
+	cfg := config.Default()
+	cfg.OrphanPolicy = config.OrphanPolicyAdopt
+	r = newFakeReconcilerWithConfig(t, cfg, ingress, stale.DeepCopy(), unrelated.DeepCopy())
+	require.NoError(t, r.SweepOrphans(context.Background()))
+
+	resource, err := getResource(t, r, "default", stale.Name)
+	require.NoError(t, err)
+	assert.Equal(t, "new-uid", resource.Labels[controller.LabelIngressUID])
+	ref := metav1.GetControllerOf(resource)
+	require.NotNil(t, ref)
+	assert.Equal(t, ingress.UID, ref.UID)
+
+	_, err = getResource(t, r, "default", unrelated.Name)
+	assert.True(t, apierrors.IsNotFound(err), "no Ingress of that name to adopt it")
+}
+
+func TestSweepOrphans_ContinuesPastFailures(t *testing.T) {
+	first := newLabeledResource("pic-default-gone-first", "default", "gone", "gone-uid")
+	second := newLabeledResource("pic-default-gone-second", "default", "gone", "gone-uid")
+
+	c, scheme := newFakeClient(t, first, second)
+	failing := interceptor.NewClient(c.(client.WithWatch), interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			if obj.GetName() == first.Name {
+				return apierrors.NewInternalError(errors.New("etcd unavailable"))
+			}
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+	r := controller.NewIngressReconciler(failing, scheme, config.Default(), logr.Discard(), record.NewFakeRecorder(100))
+
+	err := r.SweepOrphans(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), first.Name)
+
+	_, err = getResource(t, r, "default", first.Name)
+	assert.NoError(t, err)
+	_, err = getResource(t, r, "default", second.Name)
+	assert.True(t, apierrors.IsNotFound(err), "a failure does not stop the sweep")
+}
+
+func TestOwnerIndex_FindsResourcesByOwnerReference(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	nginx := "nginx"
+	ingress.Spec.IngressClassName = &nginx
+
+	// Owned through the reference only, e.g. after its labels were edited away
+	resource := ownedBy(&pangolincrd.PangolinResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "pic-default-myapp-deadbeef", Namespace: "default"},
+	}, ingress)
+
+	r := newFakeReconciler(t, ingress, resource)
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "default", Name: "myapp"},
+	})
+	require.NoError(t, err)
+
+	_, err = getResource(t, r, "default", resource.Name)
+	assert.True(t, apierrors.IsNotFound(err), "resources of an unmanaged Ingress are deleted")
+}
@@ -0,0 +1,199 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
+)
+
+func newTestNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestPolicy_ProductionRequiresSSOAndOwnedDomains(t *testing.T) {
+	policy := &piccrd.PangolinClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "security"},
+		Spec: piccrd.PangolinClusterPolicySpec{
+			NamespaceRules: []piccrd.NamespaceRule{
+				{
+					Name: "production-sso",
+					NamespaceSelector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"env": "production"},
+					},
+					RequireSSO:         true,
+					RequireBlockAccess: true,
+				},
+				{
+					Name: "team-y-domains",
+					NamespaceSelector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"kubernetes.io/metadata.name": "team-y"},
+					},
+					AllowedDomains: []string{"*.team-y.example.com"},
+				},
+			},
+		},
+	}
+	ns := newTestNamespace("team-y", map[string]string{
+		"env":                         "production",
+		"kubernetes.io/metadata.name": "team-y",
+	})
+	tunnel := newNamespacedTunnel("team-y", "default")
+
+	ingress := newMultiHostIngress("myapp", "team-y", []string{"app.team-y.example.com", "team-y.example.com"})
+	ingress.UID = "ingress-uid"
+	ingress.Annotations = map[string]string{
+		controller.AnnotationSSO:         "true",
+		controller.AnnotationBlockAccess: "true",
+	}
+
+	r := newFakeReconciler(t, policy, ns, tunnel, ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.Len(t, exp.Hosts, 2)
+
+	// Hosts are sorted: app.team-y.example.com, team-y.example.com
+	assert.NoError(t, exp.Hosts[0].Err)
+	assert.Error(t, exp.Hosts[1].Err, "the apex is outside *.team-y.example.com")
+
+	// Without SSO every host violates the production rule
+	ingress.Annotations = nil
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	for _, host := range exp.Hosts {
+		require.Error(t, host.Err)
+		assert.Contains(t, host.Err.Error(), "SSO is required")
+	}
+}
+
+func TestPolicy_TunnelRestrictedToLabeledNamespaces(t *testing.T) {
+	policy := &piccrd.PangolinClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "tunnels"},
+		Spec: piccrd.PangolinClusterPolicySpec{
+			TunnelRules: []piccrd.TunnelRule{{
+				Tunnel: "default",
+				NamespaceSelector: metav1.LabelSelector{
+					MatchLabels: map[string]string{"tunnel-access": "default"},
+				},
+			}},
+		},
+	}
+	ns := newTestNamespace("team-z", nil)
+	tunnel := newNamespacedTunnel("team-z", "default")
+
+	ingress := newTestIngress("myapp", "team-z", "app.example.com")
+	ingress.UID = "ingress-uid"
+
+	existing := &pangolincrd.PangolinResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pic-team-z-myapp-deadbeef",
+			Namespace: "team-z",
+			Labels:    map[string]string{controller.LabelIngressUID: "ingress-uid"},
+		},
+	}
+
+	r := newFakeReconciler(t, policy, ns, tunnel, ingress, existing)
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "team-z", Name: "myapp"},
+	})
+	require.NoError(t, err)
+
+	// The existing resource is withdrawn
+	var resource pangolincrd.PangolinResource
+	err = r.Get(context.Background(), types.NamespacedName{Namespace: "team-z", Name: existing.Name}, &resource)
+	assert.True(t, apierrors.IsNotFound(err))
+
+	recorder := r.Recorder.(*record.FakeRecorder)
+	assert.Contains(t, <-recorder.Events, "PolicyViolation")
+
+	// Labeling the namespace grants access
+	ns.Labels = map[string]string{"tunnel-access": "default"}
+	require.NoError(t, r.Update(context.Background(), ns))
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.NoError(t, exp.TunnelError)
+}
+
+// newSSOPolicy requires SSO for every namespace.
+func newSSOPolicy() *piccrd.PangolinClusterPolicy {
+	return &piccrd.PangolinClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "security"},
+		Spec: piccrd.PangolinClusterPolicySpec{
+			NamespaceRules: []piccrd.NamespaceRule{{Name: "sso", RequireSSO: true}},
+		},
+	}
+}
+
+func TestPolicy_PausedIngressCannotKeepViolatingResource(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	r := newFakeReconciler(t, newTestNamespace("default", nil), newTestTunnel("default"), ingress)
+
+	reconcileIngress(t, r)
+	name := r.Render(ingress, nil, "default", "")[0].Resource.Name
+	_, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+
+	// The Ingress is paused, then a policy requiring SSO is created
+	var live networkingv1.Ingress
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "myapp"}, &live))
+	live.Annotations = map[string]string{controller.AnnotationPaused: "true"}
+	require.NoError(t, r.Update(context.Background(), &live))
+	require.NoError(t, r.Create(context.Background(), newSSOPolicy()))
+	drainEvents(r)
+
+	exp, err := r.Explain(context.Background(), &live)
+	require.NoError(t, err)
+	assert.Equal(t, "none, the Ingress is paused; delete "+name+", breaking a cluster policy", exp.Action)
+
+	reconcileIngress(t, r)
+	_, err = getResource(t, r, "default", name)
+	assert.True(t, apierrors.IsNotFound(err), "the violating resource is withdrawn despite the pause")
+	events := drainEvents(r)
+	assert.Contains(t, events, "Warning PolicyViolation PangolinResource "+name+
+		`: policy violation: SSO is required (PangolinClusterPolicy security rule "sso")`)
+	assert.Contains(t, events, "Normal Deleted Deleted PangolinResource "+name+" (policy violation)")
+}
+
+func TestPolicy_AllowedManualChangesCannotBreakPolicy(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	ingress.Annotations = map[string]string{
+		controller.AnnotationSSO:                "true",
+		controller.AnnotationAllowManualChanges: "true",
+	}
+	r := newFakeReconciler(t, newSSOPolicy(), newTestNamespace("default", nil), newTestTunnel("default"), ingress)
+
+	reconcileIngress(t, r)
+	name := r.Render(ingress, nil, "default", "")[0].Resource.Name
+	resource, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	require.True(t, resource.Spec.HTTPConfig.SSO)
+
+	// Turning SSO off by hand is reverted although manual changes are allowed
+	resource.Spec.HTTPConfig.SSO = false
+	editAs(t, r, resource, "kubectl-edit", ssoField)
+	drainEvents(r)
+
+	reconcileIngress(t, r)
+	resource, err = getResource(t, r, "default", name)
+	require.NoError(t, err)
+	assert.True(t, resource.Spec.HTTPConfig.SSO, "the manual change is reverted")
+	assert.Contains(t, drainEvents(r), "Warning PolicyViolation PangolinResource "+name+
+		`: manual changes reverted: policy violation: SSO is required (PangolinClusterPolicy security rule "sso")`)
+}
@@ -11,6 +11,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
 )
@@ -355,3 +356,35 @@ func TestReconcile_DuplicateHostMergesPaths(t *testing.T) {
 	// Verify: Only ONE PangolinResource is created for app.example.com
 	// Verify: That resource has 2 targets (/ and /api)
 }
+
+func TestReconcile_SteadyStateListsOwnedResourcesByIndex(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+	reconcileIngress(t, r)
+
+	// Count the PangolinResource lists not answered by OwnerUIDIndex
+	namespaceLists := 0
+	r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			options := (&client.ListOptions{}).ApplyOptions(opts)
+			if _, ok := list.(*pangolincrd.PangolinResourceList); ok && options.FieldSelector == nil {
+				namespaceLists++
+			}
+			return c.List(ctx, list, opts...)
+		},
+	})
+
+	reconcileIngress(t, r)
+	assert.Zero(t, namespaceLists, "an Ingress whose resources exist does not list the namespace")
+
+	// A new host may adopt an existing resource, so the namespace is listed
+	require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(ingress), ingress))
+	ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1.IngressRule{
+		Host:             "api.example.com",
+		IngressRuleValue: ingress.Spec.Rules[0].IngressRuleValue,
+	})
+	require.NoError(t, r.Update(context.Background(), ingress))
+	reconcileIngress(t, r)
+	assert.Equal(t, 1, namespaceLists)
+}
@@ -0,0 +1,171 @@
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+// newRenamedResource returns a resource created for host under a name the
+// current naming scheme no longer generates.
+func newRenamedResource(name, host, ingressName, ingressUID string) *pangolincrd.PangolinResource {
+	resource := newLabeledResource(name, "default", ingressName, ingressUID)
+	resource.Annotations = map[string]string{controller.AnnotationHost: host}
+	subdomain, domain, _ := strings.Cut(host, ".")
+	resource.Spec.HTTPConfig = &pangolincrd.HTTPConfig{Subdomain: subdomain, DomainName: domain}
+	return resource
+}
+
+func TestRename_OldResourceKeptUntilReplacementIsReady(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+
+	old := ownedBy(newRenamedResource("pic-default-myapp-old", "app.example.com", "myapp", "ingress-uid"), ingress)
+	removed := ownedBy(newRenamedResource("pic-default-myapp-removed", "gone.example.com", "myapp", "ingress-uid"), ingress)
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress, old, removed)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.Len(t, exp.Hosts, 1)
+	host := exp.Hosts[0]
+	assert.NotEqual(t, old.Name, host.ResourceName, "the resource is renamed")
+	assert.Equal(t, []string{old.Name}, host.Replaces)
+	assert.Empty(t, host.Conflicts)
+	assert.Equal(t, []string{removed.Name}, exp.Orphans)
+
+	result := reconcileIngress(t, r)
+	assert.Equal(t, 15*time.Second, result.RequeueAfter, "polls until the new resource is Ready")
+
+	renamed, err := getResource(t, r, "default", host.ResourceName)
+	require.NoError(t, err)
+	assert.Equal(t, "app.example.com", renamed.Annotations[controller.AnnotationHost])
+
+	_, err = getResource(t, r, "default", old.Name)
+	assert.NoError(t, err, "the old resource serves the host until the new one is Ready")
+	_, err = getResource(t, r, "default", removed.Name)
+	assert.Error(t, err, "resources for removed hosts are deleted")
+}
+
+func TestRename_FailedReplacementStopsPolling(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	old := ownedBy(newRenamedResource("pic-default-myapp-old", "app.example.com", "myapp", "ingress-uid"), ingress)
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress, old)
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	name := exp.Hosts[0].ResourceName
+	reconcileIngress(t, r)
+	drainEvents(r)
+
+	renamed, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	renamed.Status.Phase = pangolincrd.PhaseFailed
+	renamed.Status.Conditions = []metav1.Condition{{
+		Type: "Ready", Status: metav1.ConditionFalse, Reason: "CreateFailed", Message: "domain not found",
+		LastTransitionTime: metav1.Now(),
+	}}
+	require.NoError(t, r.Update(context.Background(), renamed))
+
+	result := reconcileIngress(t, r)
+	assert.Zero(t, result.RequeueAfter, "stops polling a failed resource")
+	assert.Contains(t, drainEvents(r),
+		`Warning RenameStalled Host "app.example.com": PangolinResource `+name+` failed: domain not found; `+old.Name+` keeps serving it`)
+	_, err = getResource(t, r, "default", old.Name)
+	assert.NoError(t, err, "the old resource keeps serving the host")
+
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, []string{old.Name}, exp.Hosts[0].Replaces)
+	assert.ErrorContains(t, exp.Hosts[0].RenameErr, "domain not found")
+}
+
+func TestRename_ReplacementTimesOut(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	old := ownedBy(newRenamedResource("pic-default-myapp-old", "app.example.com", "myapp", "ingress-uid"), ingress)
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress, old)
+	r.Clock = func() time.Time { return time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC) }
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	name := exp.Hosts[0].ResourceName
+	reconcileIngress(t, r)
+
+	renamed, err := getResource(t, r, "default", name)
+	require.NoError(t, err)
+	renamed.CreationTimestamp = metav1.NewTime(time.Date(2026, 10, 18, 9, 55, 0, 0, time.UTC))
+	renamed.Status.Phase = pangolincrd.PhasePending
+	require.NoError(t, r.Update(context.Background(), renamed))
+
+	result := reconcileIngress(t, r)
+	assert.Equal(t, 15*time.Second, result.RequeueAfter, "polls within the timeout")
+
+	r.Clock = func() time.Time { return time.Date(2026, 10, 18, 10, 10, 0, 0, time.UTC) }
+	drainEvents(r)
+	result = reconcileIngress(t, r)
+	assert.Zero(t, result.RequeueAfter, "stops polling after the timeout")
+	assert.Contains(t, drainEvents(r),
+		`Warning RenameStalled Host "app.example.com": PangolinResource `+name+` is not Ready 10m0s after its creation; `+old.Name+` keeps serving it`)
+	_, err = getResource(t, r, "default", old.Name)
+	assert.NoError(t, err, "the old resource keeps serving the host")
+}
+
+func TestRename_AdoptedResourceIsNotRenamed(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+
+	adopted := ownedBy(newRenamedResource("legacy-app", "app.example.com", "myapp", "ingress-uid"), ingress)
+	adopted.Annotations[controller.AnnotationAdopt] = "true"
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress, adopted)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.Len(t, exp.Hosts, 1)
+	assert.Equal(t, adopted.Name, exp.Hosts[0].ResourceName)
+	assert.Empty(t, exp.Hosts[0].Replaces)
+}
+
+func TestRename_DeletedIngressHandsOverToSuccessor(t *testing.T) {
+	ingress := newDeletedIngress(time.Minute)
+	successor := newTestIngress("myapp-v2", "default", "app.example.com")
+	successor.UID = "successor-uid"
+
+	resource := ownedBy(newRenamedResource("pic-default-myapp-deadbeef", "app.example.com", "myapp", "ingress-uid"), ingress)
+	unrelated := ownedBy(newRenamedResource("pic-default-myapp-other", "other.example.com", "myapp", "ingress-uid"), ingress)
+
+	r := newFakeReconciler(t, ingress, successor, resource, unrelated)
+
+	reconcileIngress(t, r)
+	assert.True(t, ingressExists(t, r), "the Ingress waits for its remaining resource")
+	reconcileIngress(t, r)
+	assert.False(t, ingressExists(t, r), "the Ingress is released once that is gone")
+
+	handed, err := getResource(t, r, "default", resource.Name)
+	require.NoError(t, err)
+	owner := metav1.GetControllerOf(handed)
+	require.NotNil(t, owner)
+	assert.Equal(t, successor.UID, owner.UID)
+	assert.Equal(t, "successor-uid", handed.Labels[controller.LabelIngressUID])
+
+	_, err = getResource(t, r, "default", unrelated.Name)
+	assert.Error(t, err, "resources for hosts nobody else serves are deleted")
+
+	recorder := r.Recorder.(*record.FakeRecorder)
+	var reasons []string
+	for len(recorder.Events) > 0 {
+		reasons = append(reasons, <-recorder.Events)
+	}
+	assert.Contains(t, reasons, "Normal HandedOver Handed PangolinResource pic-default-myapp-deadbeef over to Ingress myapp-v2")
+}
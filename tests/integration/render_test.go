@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/manifest"
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files of the render tests")
+
+// renderManifests renders the Ingresses of the input file as pic render does
+// without a cluster, on Saturday 2026-10-17 at noon UTC.
+func renderManifests(t *testing.T, cfg *config.Config, input string) string {
+	t.Helper()
+	ingresses, err := manifest.ReadIngresses([]string{input}, "default")
+	require.NoError(t, err)
+
+	r := newFakeReconcilerWithConfig(t, cfg)
+	r.Clock = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+
+	var out bytes.Buffer
+	first := true
+	for i := range ingresses {
+		for _, host := range r.Render(&ingresses[i], nil, "default", "pangolin-system") {
+			if host.Err != nil {
+				out.WriteString("# " + ingresses[i].Namespace + "/" + ingresses[i].Name + ": " + host.Err.Error() + "\n")
+				continue
+			}
+			require.NoError(t, manifest.WriteResource(&out, host.Resource, first))
+			first = false
+		}
+	}
+	return out.String()
+}
+
+// assertGolden compares got with the golden file, or rewrites it with -update.
+func assertGolden(t *testing.T, golden, got string) {
+	t.Helper()
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(golden, []byte(got), 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), got)
+}
+
+func TestRender_Golden(t *testing.T) {
+	deleteExpired := config.Default()
+	deleteExpired.ExpiryAction = config.ExpiryActionDelete
+
+	tests := []struct {
+		name   string
+		cfg    *config.Config
+		golden string
+	}{
+		{name: "default config", cfg: config.Default(), golden: "ingresses.golden.yaml"},
+		{name: "expired resources deleted", cfg: deleteExpired, golden: "ingresses-expiry-delete.golden.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderManifests(t, tt.cfg, filepath.Join("testdata", "render", "ingresses.yaml"))
+			assertGolden(t, filepath.Join("testdata", "render", tt.golden), got)
+		})
+	}
+}
+
+func TestRender_ReadIngresses(t *testing.T) {
+	ingresses, err := manifest.ReadIngresses([]string{filepath.Join("testdata", "render", "ingresses.yaml")}, "staging")
+	require.NoError(t, err)
+
+	var names []string
+	for _, ingress := range ingresses {
+		names = append(names, ingress.Namespace+"/"+ingress.Name)
+	}
+	assert.Equal(t, []string{"shop/web", "staging/docs", "shop/office-hours", "shop/preview"}, names,
+		"the Service is skipped, the List expanded and the namespace defaulted")
+
+	// JSON objects decode as well
+	ingresses, err = manifest.DecodeIngresses(strings.NewReader(
+		`{"apiVersion":"networking.k8s.io/v1","kind":"Ingress","metadata":{"name":"api"}}`))
+	require.NoError(t, err)
+	require.Len(t, ingresses, 1)
+	assert.Equal(t, "api", ingresses[0].Name)
+
+	_, err = manifest.DecodeIngresses(strings.NewReader("kind: Ingress\nmetadata: [\n"))
+	assert.Error(t, err)
+}
+
+func TestRender_DiffUsesReconcilePlan(t *testing.T) {
+	policy := &piccrd.PangolinClusterPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "security"},
+		Spec: piccrd.PangolinClusterPolicySpec{
+			NamespaceRules: []piccrd.NamespaceRule{{
+				Name:              "owned-domains",
+				NamespaceSelector: metav1.LabelSelector{},
+				AllowedDomains:    []string{"*.example.com"},
+			}},
+		},
+	}
+	tunnel := newNamespacedTunnel("default", "edge")
+	tunnel.Annotations = map[string]string{controller.AnnotationDisabled: "true"}
+
+	ingress := newMultiHostIngress("myapp", "default", []string{"app.example.com", "app.example.org"})
+	ingress.Annotations = map[string]string{controller.AnnotationTunnelName: "edge"}
+
+	r := newFakeReconciler(t, policy, newTestNamespace("default", nil), tunnel, ingress)
+
+	// pic render --diff compares each host's Desired with the live resource
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.Len(t, exp.Hosts, 2)
+
+	hosts := map[string]controller.HostExplanation{}
+	for _, host := range exp.Hosts {
+		hosts[host.Host] = host
+	}
+	allowed := hosts["app.example.com"]
+	require.NoError(t, allowed.Err)
+	require.NotNil(t, allowed.Desired)
+	assert.False(t, allowed.Desired.Spec.Enabled, "turned off by the disabled tunnel")
+	assert.Nil(t, allowed.Resource, "would be created")
+
+	rejected := hosts["app.example.org"]
+	assert.Error(t, rejected.Err, "rejected by the cluster policy")
+	assert.Nil(t, rejected.Desired)
+}
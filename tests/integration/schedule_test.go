@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+)
+
+func drainEvents(r *controller.IngressReconciler) []string {
+	recorder := r.Recorder.(*record.FakeRecorder)
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	return events
+}
+
+func TestSchedule_ReportsStateAndNextTransition(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.Annotations = map[string]string{
+		controller.AnnotationSchedule:         "Mon-Fri 09:00-17:00",
+		controller.AnnotationScheduleTimezone: "Europe/Berlin",
+	}
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	// Saturday
+	r.Clock = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "hidden until 2026-10-19T09:00:00+02:00", exp.Schedule)
+
+	result := reconcileIngress(t, r)
+	assert.Equal(t, 43*time.Hour+time.Second, result.RequeueAfter, "requeued at the next transition")
+	assert.Contains(t, drainEvents(r), "Normal Scheduled Ingress is hidden until 2026-10-19T09:00:00+02:00")
+
+	resource, err := getResource(t, r, "default", r.Render(ingress, nil, "default", "")[0].Resource.Name)
+	require.NoError(t, err)
+	assert.False(t, resource.Spec.Enabled, "created disabled outside the window")
+
+	// Monday morning; the state only depends on the clock
+	r.Clock = func() time.Time { return time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC) }
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "exposed until 2026-10-19T17:00:00+02:00", exp.Schedule)
+}
+
+func TestSchedule_EventOnlyOnTransitions(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.Annotations = map[string]string{
+		controller.AnnotationSchedule:         "Mon-Fri 09:00-17:00",
+		controller.AnnotationScheduleTimezone: "Europe/Berlin",
+	}
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	// Saturday
+	r.Clock = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+	reconcileIngress(t, r)
+	assert.Contains(t, drainEvents(r), "Normal Scheduled Ingress is hidden until 2026-10-19T09:00:00+02:00")
+
+	// Sunday, same window state and next transition
+	r.Clock = func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) }
+	reconcileIngress(t, r)
+	assert.Empty(t, drainEvents(r), "no event while the window is unchanged")
+
+	// Monday morning, the window opened
+	r.Clock = func() time.Time { return time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC) }
+	reconcileIngress(t, r)
+	assert.Contains(t, drainEvents(r), "Normal Scheduled Ingress is exposed until 2026-10-19T17:00:00+02:00")
+}
+
+func TestSchedule_InvalidScheduleKeepsIngressHidden(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.Annotations = map[string]string{controller.AnnotationSchedule: "weekdays"}
+
+	r := newFakeReconciler(t, newTestTunnel("default"), ingress)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Contains(t, exp.Schedule, "hidden: invalid schedule")
+
+	reconcileIngress(t, r)
+	events := drainEvents(r)
+	require.NotEmpty(t, events)
+	assert.Contains(t, events[0], "InvalidSchedule")
+
+	resource, err := getResource(t, r, "default", r.Render(ingress, nil, "default", "")[0].Resource.Name)
+	require.NoError(t, err)
+	assert.False(t, resource.Spec.Enabled)
+}
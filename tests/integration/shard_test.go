@@ -0,0 +1,147 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+)
+
+func TestShard_SelectorLeavesOtherIngressesAlone(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	ingress.Labels = map[string]string{"team": "payments"}
+
+	// Would otherwise be deleted as the resource of a removed host
+	stale := ownedBy(newRenamedResource("pic-default-myapp-stale", "gone.example.com", "myapp", "ingress-uid"), ingress)
+
+	cfg := config.Default()
+	cfg.ShardSelector = "team=search"
+	r := newFakeReconcilerWithConfig(t, cfg, newTestTunnel("default"), ingress, stale)
+
+	result := reconcileIngress(t, r)
+	assert.Zero(t, result)
+
+	_, err := getResource(t, r, "default", stale.Name)
+	assert.NoError(t, err)
+	assert.Empty(t, drainEvents(r))
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, `labels do not match shardSelector "team=search"`, exp.Shard)
+
+	ingress.Labels["team"] = "search"
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Empty(t, exp.Shard)
+}
+
+func TestShard_Tunnels(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	stale := ownedBy(newRenamedResource("pic-default-myapp-stale", "gone.example.com", "myapp", "ingress-uid"), ingress)
+
+	cfg := config.Default()
+	cfg.ShardTunnels = []string{"site-a", "edge/default"}
+	r := newFakeReconcilerWithConfig(t, cfg, newNamespacedTunnel("default", "default"), ingress, stale)
+
+	result := reconcileIngress(t, r)
+	assert.Zero(t, result)
+	_, err := getResource(t, r, "default", stale.Name)
+	assert.NoError(t, err, "the Ingress uses a tunnel of another shard")
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "tunnel default/default is not in shardTunnels site-a, edge/default", exp.Shard)
+
+	cfg = config.Default()
+	cfg.ShardTunnels = []string{"default"}
+	r.UpdateConfig(cfg)
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Empty(t, exp.Shard, "a bare name matches the tunnel in any namespace")
+}
+
+func TestShard_IngressMovesBetweenShards(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.Labels = map[string]string{"team": "payments"}
+
+	cfg := config.Default()
+	cfg.ShardSelector, cfg.InstanceID = "team=payments", "payments"
+	payments := newFakeReconcilerWithConfig(t, cfg, newTestTunnel("default"), ingress)
+	cfg = config.Default()
+	cfg.ShardSelector, cfg.InstanceID = "team=search", "search"
+	search := controller.NewIngressReconciler(payments.Client, payments.Scheme, cfg, logr.Discard(), record.NewFakeRecorder(100))
+
+	reconcileIngress(t, payments)
+	name := payments.Render(ingress, nil, "default", "")[0].Resource.Name
+	resource, err := getResource(t, payments, "default", name)
+	require.NoError(t, err)
+	assert.Equal(t, "payments", resource.Labels[controller.LabelInstance])
+
+	// Relabeled into the search shard
+	require.NoError(t, payments.Get(context.Background(), client.ObjectKeyFromObject(ingress), ingress))
+	ingress.Labels["team"] = "search"
+	require.NoError(t, payments.Update(context.Background(), ingress))
+
+	exp, err := payments.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "delete this instance's PangolinResources, left to another PIC instance", exp.Action)
+	assert.Equal(t, []string{name}, exp.Orphans)
+
+	reconcileIngress(t, search)
+	resource, err = getResource(t, search, "default", name)
+	require.NoError(t, err)
+	assert.Equal(t, "payments", resource.Labels[controller.LabelInstance], "the new shard leaves the old instance's resource alone")
+
+	drainEvents(payments)
+	reconcileIngress(t, payments)
+	assert.Contains(t, drainEvents(payments), "Normal Deleted Deleted PangolinResource "+name+" (outside shard)")
+
+	reconcileIngress(t, search)
+	resource, err = getResource(t, search, "default", name)
+	require.NoError(t, err)
+	assert.Equal(t, "search", resource.Labels[controller.LabelInstance], "the new shard takes over")
+}
+
+func TestShard_UnlabeledResourcesAreLeftForTakeOver(t *testing.T) {
+	ingress := newTestIngress("myapp", "default", "app.example.com")
+	ingress.UID = "ingress-uid"
+	ingress.Labels = map[string]string{"team": "search"}
+	resource := ownedBy(newRenamedResource("pic-default-myapp-old", "app.example.com", "myapp", "ingress-uid"), ingress)
+
+	cfg := config.Default()
+	cfg.ShardSelector, cfg.InstanceID = "team=payments", "payments"
+	r := newFakeReconcilerWithConfig(t, cfg, newTestTunnel("default"), ingress, resource)
+
+	reconcileIngress(t, r)
+	_, err := getResource(t, r, "default", resource.Name)
+	assert.NoError(t, err, "a resource without an instance label may be the other instance's")
+}
+
+func TestShard_DeletedIngressOutsideShardIsFinalized(t *testing.T) {
+	// The Ingress left the shard while holding the cleanup finalizer
+	ingress := newDeletedIngress(time.Minute)
+	ingress.Labels = map[string]string{"team": "search"}
+	resource := ownedBy(newLabeledResource("pic-default-myapp-deadbeef", "default", "myapp", "ingress-uid"), ingress)
+
+	cfg := config.Default()
+	cfg.ShardSelector = "team=payments"
+	r := newFakeReconcilerWithConfig(t, cfg, newTestTunnel("default"), ingress, resource)
+
+	reconcileIngress(t, r)
+	_, err := getResource(t, r, "default", resource.Name)
+	assert.True(t, apierrors.IsNotFound(err), "the resource is deleted")
+
+	reconcileIngress(t, r)
+	assert.False(t, ingressExists(t, r), "the Ingress is released")
+}
@@ -0,0 +1,278 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/config"
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/piccrd"
+)
+
+func newNamespacedTunnel(namespace, name string) *pangolincrd.PangolinTunnel {
+	tunnel := newTestTunnel(name)
+	tunnel.Namespace = namespace
+	return tunnel
+}
+
+func newTunnelGrant(namespace, name string, from []string, to ...string) *piccrd.PangolinTunnelGrant {
+	grant := &piccrd.PangolinTunnelGrant{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+	for _, ns := range from {
+		grant.Spec.From = append(grant.Spec.From, piccrd.TunnelGrantFrom{Namespace: ns})
+	}
+	for _, tunnel := range to {
+		grant.Spec.To = append(grant.Spec.To, piccrd.TunnelGrantTo{Name: tunnel})
+	}
+	return grant
+}
+
+// lookupTunnel resolves a tunnel reference for an Ingress in namespace as
+// Reconcile does, returning the tunnel's name and namespace.
+func lookupTunnel(t testing.TB, r *controller.IngressReconciler, namespace, ref string) (string, string, error) {
+	t.Helper()
+	ingress := newTestIngress("myapp", namespace, "app.example.com")
+	ingress.Annotations = map[string]string{controller.AnnotationTunnelName: ref}
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	return exp.TunnelName, exp.TunnelNamespace, exp.TunnelError
+}
+
+func TestTunnel_BareNamePrefersIngressNamespace(t *testing.T) {
+	ingress := newTestIngress("myapp", "team-a", "app.example.com")
+
+	r := newFakeReconciler(t,
+		newNamespacedTunnel("pangolin-system", "default"),
+		newNamespacedTunnel("team-a", "default"),
+		newTunnelGrant("pangolin-system", "shared", []string{"team-b"}),
+		ingress,
+	)
+
+	name, namespace, err := lookupTunnel(t, r, "team-a", "default")
+	require.NoError(t, err)
+	assert.Equal(t, "default", name)
+	assert.Equal(t, "team-a", namespace)
+
+	name, namespace, err = lookupTunnel(t, r, "team-b", "default")
+	require.NoError(t, err)
+	assert.Equal(t, "default", name)
+	assert.Equal(t, "pangolin-system", namespace, "other namespaces are tried in name order")
+}
+
+func TestTunnel_QualifiedReference(t *testing.T) {
+	ingress := newTestIngress("myapp", "team-a", "app.example.com")
+	ingress.Annotations = map[string]string{controller.AnnotationTunnelName: "edge/tunnel-eu"}
+
+	r := newFakeReconciler(t,
+		newNamespacedTunnel("edge", "tunnel-eu"),
+		newNamespacedTunnel("team-a", "tunnel-eu"),
+		newTunnelGrant("edge", "team-a", []string{"team-a"}),
+		ingress,
+	)
+
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.NoError(t, exp.TunnelError)
+	assert.Equal(t, "tunnel-eu", exp.TunnelName)
+	assert.Equal(t, "edge", exp.TunnelNamespace)
+
+	_, _, err = lookupTunnel(t, r, "team-a", "missing/tunnel-eu")
+	assert.Error(t, err)
+
+	_, _, err = lookupTunnel(t, r, "team-a", "edge/")
+	assert.Error(t, err)
+}
+
+func TestTunnel_GrantRestrictsCrossNamespaceReferences(t *testing.T) {
+	ingress := newTestIngress("myapp", "team-b", "app.example.com")
+	ingress.UID = "ingress-uid"
+	ingress.Annotations = map[string]string{controller.AnnotationTunnelName: "edge/tunnel-eu"}
+
+	grant := newTunnelGrant("edge", "team-a", []string{"team-a"}, "tunnel-eu")
+	existing := &pangolincrd.PangolinResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pic-team-b-myapp-deadbeef",
+			Namespace: "team-b",
+			Labels:    map[string]string{controller.LabelIngressUID: "ingress-uid"},
+		},
+	}
+
+	r := newFakeReconciler(t, newNamespacedTunnel("edge", "tunnel-eu"), grant, ingress, existing)
+
+	// team-b is not granted: everything is withdrawn
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	require.Error(t, exp.TunnelError)
+	assert.Contains(t, exp.TunnelError.Error(), "not permitted")
+	assert.Equal(t, []string{existing.Name}, exp.Orphans)
+
+	// The grant's own namespace list is what matters, not the tunnel list
+	_, _, err = lookupTunnel(t, r, "team-a", "edge/tunnel-eu")
+	assert.NoError(t, err)
+
+	grant.Spec.From = append(grant.Spec.From, piccrd.TunnelGrantFrom{Namespace: "team-b"})
+	require.NoError(t, r.Update(context.Background(), grant))
+
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.NoError(t, exp.TunnelError)
+	assert.Equal(t, "edge", exp.TunnelNamespace)
+}
+
+func TestTunnel_RequireTunnelGrants(t *testing.T) {
+	r := newFakeReconciler(t, newNamespacedTunnel("edge", "default"), newNamespacedTunnel("team-a", "local"))
+
+	_, _, err := lookupTunnel(t, r, "team-a", "default")
+	assert.Error(t, err, "cross-namespace references need a grant")
+
+	_, namespace, err := lookupTunnel(t, r, "team-a", "local")
+	require.NoError(t, err, "same-namespace references never need a grant")
+	assert.Equal(t, "team-a", namespace)
+
+	require.NoError(t, r.Create(context.Background(), newTunnelGrant("edge", "all", []string{"team-a"})))
+	_, namespace, err = lookupTunnel(t, r, "team-a", "default")
+	require.NoError(t, err)
+	assert.Equal(t, "edge", namespace)
+
+	// Turned off, namespaces without grants may be referenced from any namespace
+	cfg := config.Default()
+	cfg.RequireTunnelGrants = false
+	r = newFakeReconcilerWithConfig(t, cfg, newNamespacedTunnel("edge", "default"))
+	_, namespace, err = lookupTunnel(t, r, "team-a", "default")
+	require.NoError(t, err)
+	assert.Equal(t, "edge", namespace)
+}
+
+func TestTunnel_DuplicateNamesAreAmbiguousUnlessPreferred(t *testing.T) {
+	ingress := newTestIngress("myapp", "team-a", "app.example.com")
+	ingress.UID = "ingress-uid"
+	tunnels := []client.Object{
+		newNamespacedTunnel("zone-b", "default"),
+		newNamespacedTunnel("zone-a", "default"),
+		newNamespacedTunnel("pangolin-system", "default"),
+		newTunnelGrant("zone-b", "team-a", []string{"team-a"}),
+		newTunnelGrant("zone-a", "team-a", []string{"team-a"}),
+		newTunnelGrant("pangolin-system", "team-a", []string{"team-a"}),
+		ingress,
+	}
+
+	// Without a preferred namespace the first by name wins, with a warning
+	r := newFakeReconciler(t, tunnels...)
+	for i := 0; i < 3; i++ {
+		exp, err := r.Explain(context.Background(), ingress)
+		require.NoError(t, err)
+		require.NoError(t, exp.TunnelError)
+		assert.Equal(t, "pangolin-system", exp.TunnelNamespace)
+		assert.Equal(t, []string{"zone-a", "zone-b"}, exp.TunnelAlternatives)
+	}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "myapp"},
+	})
+	require.NoError(t, err)
+	recorder := r.Recorder.(*record.FakeRecorder)
+	assert.Contains(t, <-recorder.Events, "AmbiguousTunnel")
+	resource, err := getResource(t, r, "team-a", r.Render(ingress, nil, "default", "")[0].Resource.Name)
+	require.NoError(t, err)
+	assert.Equal(t, pangolincrd.TunnelRef{Name: "default", Namespace: "pangolin-system"}, resource.Spec.TunnelRef)
+
+	// The controller namespace is preferred by default
+	cfg := config.Default()
+	cfg.ControllerNamespace = "zone-b"
+	r = newFakeReconcilerWithConfig(t, cfg, tunnels...)
+	exp, err := r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "zone-b", exp.TunnelNamespace)
+	assert.Empty(t, exp.TunnelAlternatives)
+
+	// An explicit preference overrides it
+	cfg = config.Default()
+	cfg.ControllerNamespace = "zone-b"
+	cfg.TunnelPreference = []string{config.PreferIngressNamespace, "zone-a", config.PreferControllerNamespace}
+	r = newFakeReconcilerWithConfig(t, cfg, tunnels...)
+	exp, err = r.Explain(context.Background(), ingress)
+	require.NoError(t, err)
+	assert.Equal(t, "zone-a", exp.TunnelNamespace)
+	assert.Empty(t, exp.TunnelAlternatives)
+}
+
+// BenchmarkTunnelLookup compares the indexed tunnel lookup with the full
+// list-and-scan it replaced. The indexed lookup runs through Explain, so it
+// also counts planning the Ingress. The fake client evaluates indexes by
+// scanning, so through it the gain is only the smaller result list; the
+// store benchmarks show the informer indexer the manager's cache serves from.
+func BenchmarkTunnelLookup(b *testing.B) {
+	ctx := context.Background()
+	for _, n := range []int{100, 1000} {
+		objs := make([]client.Object, 0, n)
+		for i := 0; i < n; i++ {
+			objs = append(objs, newNamespacedTunnel(fmt.Sprintf("ns-%d", i%10), fmt.Sprintf("tunnel-%d", i)))
+		}
+		r := newFakeReconciler(b, objs...)
+
+		b.Run(fmt.Sprintf("indexed/tunnels=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := lookupTunnel(b, r, "ns-0", "tunnel-0"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("full-list/tunnels=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var tunnels pangolincrd.PangolinTunnelList
+				if err := r.List(ctx, &tunnels); err != nil {
+					b.Fatal(err)
+				}
+				found := false
+				for _, tunnel := range tunnels.Items {
+					found = found || tunnel.Name == "tunnel-0"
+				}
+				if !found {
+					b.Fatal("tunnel-0 not found")
+				}
+			}
+		})
+
+		store := toolscache.NewIndexer(toolscache.MetaNamespaceKeyFunc, toolscache.Indexers{
+			controller.TunnelNameIndex: func(obj interface{}) ([]string, error) {
+				return controller.IndexTunnelName(obj.(client.Object)), nil
+			},
+		})
+		for _, obj := range objs {
+			require.NoError(b, store.Add(obj))
+		}
+
+		b.Run(fmt.Sprintf("store-indexed/tunnels=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if tunnels, err := store.ByIndex(controller.TunnelNameIndex, "tunnel-0"); err != nil || len(tunnels) != 1 {
+					b.Fatal("tunnel-0 not found", err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("store-scan/tunnels=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				found := false
+				for _, obj := range store.List() {
+					found = found || obj.(client.Object).GetName() == "tunnel-0"
+				}
+				if !found {
+					b.Fatal("tunnel-0 not found")
+				}
+			}
+		})
+	}
+}
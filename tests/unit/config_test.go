@@ -2,9 +2,11 @@ package unit
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/wizzz/pangolin-ingress-controller/internal/config"
@@ -56,6 +58,30 @@ func TestLoadConfig_TunnelMapping(t *testing.T) {
 	assert.Equal(t, "staging-tunnel", cfg.TunnelMapping["staging"])
 }
 
+func TestLoadConfig_QualifiedTunnelReferences(t *testing.T) {
+	os.Setenv("PIC_DEFAULT_TUNNEL_NAME", "pangolin-system/default")
+	os.Setenv("PIC_TUNNEL_CLASS_MAPPING", "eu=edge/tunnel-eu")
+	defer os.Clearenv()
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "pangolin-system/default", cfg.DefaultTunnelName)
+	assert.Equal(t, "edge/tunnel-eu", cfg.TunnelMapping["eu"])
+	assert.True(t, cfg.RequireTunnelGrants, "grants are required by default")
+	assert.Equal(t, []string{config.PreferIngressNamespace, config.PreferControllerNamespace}, cfg.TunnelPreference)
+
+	os.Setenv("PIC_REQUIRE_TUNNEL_GRANTS", "false")
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.RequireTunnelGrants)
+
+	os.Unsetenv("PIC_REQUIRE_TUNNEL_GRANTS")
+	os.Setenv("PIC_DEFAULT_TUNNEL_NAME", "a/b/c")
+	_, err = config.Load()
+	assert.Error(t, err)
+}
+
 func TestLoadConfig_InvalidResyncPeriod(t *testing.T) {
 	os.Setenv("PIC_RESYNC_PERIOD", "invalid")
 	defer os.Clearenv()
@@ -63,3 +89,151 @@ func TestLoadConfig_InvalidResyncPeriod(t *testing.T) {
 	_, err := config.Load()
 	assert.Error(t, err)
 }
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pic.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+defaultTunnelName: main
+tunnelMapping:
+  eu: tunnel-eu
+`), 0o600))
+
+	cfg, err := config.LoadFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "main", cfg.DefaultTunnelName)
+	assert.Equal(t, "http", cfg.BackendScheme)
+	assert.Equal(t, "tunnel-eu", cfg.TunnelMapping["eu"])
+}
+
+func TestLoadFile_UnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pic.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("defaultTunnel: main\n"), 0o600))
+
+	_, err := config.LoadFile(path)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_MalformedTunnelMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+	}{
+		{name: "missing separator", mapping: "eu=tunnel-eu\nus"},
+		{name: "empty tunnel", mapping: "eu="},
+		{name: "empty suffix", mapping: "=tunnel-eu"},
+		{name: "duplicate suffix", mapping: "eu=tunnel-eu\neu=tunnel-eu2"},
+		{name: "invalid tunnel name", mapping: "eu=Tunnel_EU"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv("PIC_TUNNEL_CLASS_MAPPING", tt.mapping)
+			defer os.Clearenv()
+
+			_, err := config.Load()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadConfig_FileWithEnvOverrides(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+apiVersion: pic.ingress.k8s.io/v1alpha1
+kind: ControllerConfig
+defaultTunnelName: main
+backendScheme: https
+resyncPeriod: 10m
+logLevel: debug
+watchNamespaces: [apps]
+tunnelMapping:
+  eu: tunnel-eu
+`), 0o600))
+
+	os.Setenv("PIC_CONFIG_FILE", path)
+	os.Setenv("PIC_DEFAULT_TUNNEL_NAME", "override")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "override", cfg.DefaultTunnelName)
+	assert.Equal(t, "https", cfg.BackendScheme)
+	assert.Equal(t, 10*time.Minute, cfg.ResyncPeriod)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, []string{"apps"}, cfg.WatchNamespaces)
+	assert.Equal(t, map[string]string{"eu": "tunnel-eu"}, cfg.TunnelMapping)
+}
+
+func TestLoadFile_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unsupported version", content: "apiVersion: pic.ingress.k8s.io/v2\n"},
+		{name: "wrong kind", content: "kind: Deployment\n"},
+		{name: "invalid backend scheme", content: "backendScheme: tcp\n"},
+		{name: "invalid log level", content: "logLevel: verbose\n"},
+		{name: "invalid resync period", content: "resyncPeriod: soon\n"},
+		{name: "invalid mapping key", content: "tunnelMapping:\n  EU_West: tunnel-eu\n"},
+		{name: "invalid tunnel preference", content: "tunnelPreference: [$ingress, $pod]\n"},
+		{name: "invalid orphan policy", content: "orphanPolicy: keep\n"},
+		{name: "negative orphan sweep interval", content: "orphanSweepInterval: -1m\n"},
+		{name: "negative finalizer timeout", content: "finalizerTimeout: -1m\n"},
+		{name: "invalid expiry action", content: "expiryAction: archive\n"},
+		{name: "invalid shard selector", content: "shardSelector: \"team in (a\"\n"},
+		{name: "invalid shard tunnel", content: "shardTunnels: [Site_A]\n"},
+		{name: "invalid instance id", content: "instanceID: \"prod/eu\"\n"},
+		{name: "management kubeconfig without cluster name", content: "managementKubeconfig: /etc/pic-management/kubeconfig\n"},
+		{name: "cluster name without management kubeconfig", content: "clusterName: edge-1\n"},
+		{name: "invalid cluster name", content: "managementKubeconfig: /etc/pic-management/kubeconfig\nclusterName: Edge_1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pic.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			_, err := config.LoadFile(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWatcher_ReloadNotifiesOnChange(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("tunnelMapping:\n  eu: tunnel-eu\n"), 0o600))
+	os.Setenv("PIC_CONFIG_FILE", path)
+
+	initial, err := config.Load()
+	require.NoError(t, err)
+
+	watcher := config.NewWatcher(path, initial, logr.Discard())
+	var calls int
+	watcher.OnChange(func(previous, next *config.Config) {
+		calls++
+		assert.False(t, config.TunnelSettingsEqual(previous, next))
+	})
+
+	// Unchanged file does not notify
+	watcher.Reload()
+	assert.Equal(t, 0, calls)
+
+	// Invalid file keeps the previous configuration
+	require.NoError(t, os.WriteFile(path, []byte("tunnelMapping:\n  eu: \"\"\n"), 0o600))
+	watcher.Reload()
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, "tunnel-eu", watcher.Current().TunnelMapping["eu"])
+
+	require.NoError(t, os.WriteFile(path, []byte("tunnelMapping:\n  eu: tunnel-eu-2\n"), 0o600))
+	watcher.Reload()
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "tunnel-eu-2", watcher.Current().TunnelMapping["eu"])
+}
@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+	"github.com/wizzz/pangolin-ingress-controller/internal/util"
+)
+
+func TestDiff_NoChanges(t *testing.T) {
+	spec := pangolincrd.PangolinResourceSpec{
+		Name:       "default/app/app.example.com",
+		Enabled:    true,
+		HTTPConfig: &pangolincrd.HTTPConfig{DomainName: "example.com", Subdomain: "app"},
+	}
+
+	diffs, err := util.Diff("spec", spec, spec)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+func TestDiff_FieldChanges(t *testing.T) {
+	current := pangolincrd.PangolinResourceSpec{
+		Enabled:    true,
+		HTTPConfig: &pangolincrd.HTTPConfig{DomainName: "example.com", SSO: false},
+		Targets:    []pangolincrd.Target{{IP: "app.default.svc.cluster.local", Port: 80}},
+	}
+	desired := pangolincrd.PangolinResourceSpec{
+		Enabled:    true,
+		HTTPConfig: &pangolincrd.HTTPConfig{DomainName: "example.com", SSO: true},
+		Targets:    []pangolincrd.Target{{IP: "app.default.svc.cluster.local", Port: 8080}},
+	}
+
+	diffs, err := util.Diff("spec", current, desired)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"spec.httpConfig.sso: false -> true",
+		"spec.targets[0].port: 80 -> 8080",
+	}, diffs)
+}
+
+func TestDiff_NilHTTPConfig(t *testing.T) {
+	current := pangolincrd.PangolinResourceSpec{Enabled: true}
+	desired := pangolincrd.PangolinResourceSpec{
+		Enabled:    true,
+		HTTPConfig: &pangolincrd.HTTPConfig{DomainName: "example.com"},
+	}
+
+	diffs, err := util.Diff("spec", current, desired)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	assert.Contains(t, diffs[0], "spec.httpConfig: <unset> -> ")
+}
+
+func TestDiff_LabelKeys(t *testing.T) {
+	current := map[string]string{"pic.ingress.k8s.io/name": "old"}
+	desired := map[string]string{"pic.ingress.k8s.io/name": "new"}
+
+	diffs, err := util.Diff("metadata.labels", current, desired)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`metadata.labels[pic.ingress.k8s.io/name]: "old" -> "new"`}, diffs)
+}
@@ -0,0 +1,110 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/controller"
+	"github.com/wizzz/pangolin-ingress-controller/internal/metrics"
+	"github.com/wizzz/pangolin-ingress-controller/internal/pangolincrd"
+)
+
+func TestManagedIngresses_TracksClassAndTunnel(t *testing.T) {
+	metrics.SetIngressManaged("default/app", "pangolin", "default")
+	metrics.SetIngressManaged("default/api", "pangolin", "default")
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.ManagedIngresses.WithLabelValues("pangolin", "default")))
+
+	// Moving an Ingress to another tunnel moves it between series
+	metrics.SetIngressManaged("default/api", "pangolin-eu", "tunnel-eu")
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ManagedIngresses.WithLabelValues("pangolin", "default")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ManagedIngresses.WithLabelValues("pangolin-eu", "tunnel-eu")))
+
+	// Repeated reconciles do not double count
+	metrics.SetIngressManaged("default/app", "pangolin", "default")
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ManagedIngresses.WithLabelValues("pangolin", "default")))
+
+	metrics.SetIngressUnmanaged("default/app")
+	metrics.SetIngressUnmanaged("default/api")
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.ManagedIngresses.WithLabelValues("pangolin", "default")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.ManagedIngresses.WithLabelValues("pangolin-eu", "tunnel-eu")))
+}
+
+func TestIngressExpiry_AggregatesPendingExpiries(t *testing.T) {
+	early := time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC)
+	late := time.Date(2026, 10, 19, 11, 0, 0, 0, time.UTC)
+
+	metrics.SetIngressExpiry("default/app", late)
+	metrics.SetIngressExpiry("default/api", early)
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.ExpiringIngresses))
+	assert.Equal(t, float64(early.Unix()), testutil.ToFloat64(metrics.NextIngressExpiry))
+
+	// Repeated reconciles do not double count
+	metrics.SetIngressExpiry("default/api", early)
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.ExpiringIngresses))
+
+	metrics.SetIngressExpiry("default/api", time.Time{})
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ExpiringIngresses))
+	assert.Equal(t, float64(late.Unix()), testutil.ToFloat64(metrics.NextIngressExpiry))
+
+	metrics.SetIngressExpiry("default/app", time.Time{})
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.ExpiringIngresses))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.NextIngressExpiry))
+}
+
+func TestResourceReadyDuration_ObservedOnce(t *testing.T) {
+	before := readySampleCount(t)
+
+	metrics.StartReadyTimer("default/pic-default-app-12345678")
+	metrics.ObserveReady("default/pic-default-app-12345678")
+	metrics.ObserveReady("default/pic-default-app-12345678")
+
+	assert.Equal(t, before+1, readySampleCount(t))
+}
+
+func readySampleCount(t *testing.T) uint64 {
+	t.Helper()
+	var m dto.Metric
+	require.NoError(t, metrics.ResourceReadyDuration.Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestResourceCollector_CountsSelectedResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, pangolincrd.AddToScheme(scheme))
+	resource := func(name, cluster, phase string) *pangolincrd.PangolinResource {
+		return &pangolincrd.PangolinResource{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{
+				controller.LabelIngressUID: "uid-" + name,
+				controller.LabelCluster:    cluster,
+			}},
+			Status: pangolincrd.PangolinResourceStatus{Phase: phase},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		resource("a", "edge-1", pangolincrd.PhaseReady),
+		resource("b", "edge-1", pangolincrd.PhaseFailed),
+		resource("c", "edge-2", pangolincrd.PhaseReady),
+	).Build()
+
+	// Another cluster's resources in the management cluster are not counted
+	collector := metrics.NewResourceCollector(c,
+		client.HasLabels{controller.LabelIngressUID},
+		client.MatchingLabels{controller.LabelCluster: "edge-1"})
+	expected := `
+# HELP pic_pangolin_resources Number of PangolinResources managed by PIC, by phase.
+# TYPE pic_pangolin_resources gauge
+pic_pangolin_resources{phase="Failed"} 1
+pic_pangolin_resources{phase="Ready"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
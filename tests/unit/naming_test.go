@@ -1,6 +1,7 @@
 package unit
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -72,3 +73,32 @@ func TestGenerateName_ValidKubernetesName(t *testing.T) {
 	assert.NotEqual(t, '-', name[0])
 	assert.NotEqual(t, '-', name[len(name)-1])
 }
+
+func TestGenerateLongName(t *testing.T) {
+	short := util.GenerateName("default", "myapp", "app.example.com")
+	long := util.GenerateLongName("default", "myapp", "app.example.com")
+
+	// The long hash extends the short one
+	assert.True(t, strings.HasPrefix(long, short))
+	assert.Len(t, long, len(short)+8)
+
+	long = util.GenerateLongName("a-very-long-namespace-name", "a-very-long-ingress-name-for-testing", "app.example.com")
+	assert.LessOrEqual(t, len(long), 63)
+}
+
+func TestGenerateClusterName(t *testing.T) {
+	name := util.GenerateClusterName("edge-1", "default", "myapp", "app.example.com")
+	assert.True(t, strings.HasPrefix(name, "pic-edge-1-default-myapp-"), name)
+
+	// The same Ingress in another cluster gets another name
+	assert.NotEqual(t, name, util.GenerateClusterName("edge-2", "default", "myapp", "app.example.com"))
+
+	// Without a cluster the single-cluster name is kept
+	assert.Equal(t, util.GenerateName("default", "myapp", "app.example.com"),
+		util.GenerateClusterName("", "default", "myapp", "app.example.com"))
+
+	long := util.GenerateLongClusterName("edge-1", "default", "myapp", "app.example.com")
+	assert.True(t, strings.HasPrefix(long, name))
+	assert.LessOrEqual(t, len(util.GenerateLongClusterName("a-very-long-cluster-name", "a-very-long-namespace-name",
+		"a-very-long-ingress-name-for-testing", "app.example.com")), 63)
+}
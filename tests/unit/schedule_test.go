@@ -0,0 +1,106 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wizzz/pangolin-ingress-controller/internal/util"
+)
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestParseSchedule_TimeRanges(t *testing.T) {
+	s, err := util.ParseSchedule("Mon-Fri 09:00-17:00", "Europe/Berlin")
+	require.NoError(t, err)
+
+	// Friday 2026-10-16, CEST (UTC+2)
+	assert.False(t, s.Active(mustTime(t, "2026-10-16T06:59:00Z")))
+	assert.True(t, s.Active(mustTime(t, "2026-10-16T07:00:00Z")))
+	assert.True(t, s.Active(mustTime(t, "2026-10-16T14:59:00Z")))
+	assert.False(t, s.Active(mustTime(t, "2026-10-16T15:00:00Z")))
+
+	next, ok := s.NextTransition(mustTime(t, "2026-10-16T12:00:00Z"))
+	require.True(t, ok)
+	assert.True(t, next.Equal(mustTime(t, "2026-10-16T15:00:00Z")))
+
+	// Over the weekend, and into CET (UTC+1) after the DST change on Oct 25
+	next, ok = s.NextTransition(mustTime(t, "2026-10-16T15:00:00Z"))
+	require.True(t, ok)
+	assert.True(t, next.Equal(mustTime(t, "2026-10-19T07:00:00Z")))
+	next, ok = s.NextTransition(mustTime(t, "2026-10-23T16:00:00Z"))
+	require.True(t, ok)
+	assert.True(t, next.Equal(mustTime(t, "2026-10-26T08:00:00Z")))
+}
+
+func TestParseSchedule_OvernightAndMultipleRanges(t *testing.T) {
+	s, err := util.ParseSchedule("Fri 22:00-06:00; Sat,Sun 10:00-12:00", "")
+	require.NoError(t, err)
+
+	assert.True(t, s.Active(mustTime(t, "2026-10-16T23:00:00Z")), "Friday night")
+	assert.True(t, s.Active(mustTime(t, "2026-10-17T05:59:00Z")), "into Saturday")
+	assert.False(t, s.Active(mustTime(t, "2026-10-17T06:00:00Z")))
+	assert.True(t, s.Active(mustTime(t, "2026-10-18T11:00:00Z")), "Sunday")
+	assert.False(t, s.Active(mustTime(t, "2026-10-15T23:00:00Z")), "Thursday night")
+
+	next, ok := s.NextTransition(mustTime(t, "2026-10-17T06:00:00Z"))
+	require.True(t, ok)
+	assert.True(t, next.Equal(mustTime(t, "2026-10-17T10:00:00Z")))
+}
+
+func TestParseSchedule_Cron(t *testing.T) {
+	s, err := util.ParseSchedule("* 9-16 * * mon-fri", "America/New_York")
+	require.NoError(t, err)
+
+	// Friday 2026-10-16, EDT (UTC-4)
+	assert.True(t, s.Active(mustTime(t, "2026-10-16T13:00:00Z")))
+	assert.True(t, s.Active(mustTime(t, "2026-10-16T20:59:00Z")))
+	assert.False(t, s.Active(mustTime(t, "2026-10-16T21:00:00Z")))
+
+	next, ok := s.NextTransition(mustTime(t, "2026-10-16T13:00:30Z"))
+	require.True(t, ok)
+	assert.True(t, next.Equal(mustTime(t, "2026-10-16T21:00:00Z")))
+
+	next, ok = s.NextTransition(mustTime(t, "2026-10-16T21:00:00Z"))
+	require.True(t, ok)
+	assert.True(t, next.Equal(mustTime(t, "2026-10-19T13:00:00Z")))
+
+	// Steps and lists
+	s, err = util.ParseSchedule("0-29 */6 1,15 * *", "")
+	require.NoError(t, err)
+	assert.True(t, s.Active(mustTime(t, "2026-10-15T12:10:00Z")))
+	assert.False(t, s.Active(mustTime(t, "2026-10-15T12:30:00Z")))
+	assert.False(t, s.Active(mustTime(t, "2026-10-16T12:10:00Z")))
+}
+
+func TestParseSchedule_NeverChanges(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "00:00-24:00", "* * 30 2 *"} {
+		s, err := util.ParseSchedule(spec, "")
+		require.NoError(t, err, spec)
+		_, ok := s.NextTransition(mustTime(t, "2026-10-16T12:00:00Z"))
+		assert.False(t, ok, spec)
+	}
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, tc := range []struct{ spec, timezone string }{
+		{"", ""},
+		{"Mon-Fri 09:00-17:00", "Mars/Olympus"},
+		{"09:00-09:00", ""},
+		{"25:00-26:00", ""},
+		{"Funday 09:00-17:00", ""},
+		{"60 * * * *", ""},
+		{"* * * *", ""},
+		{"*/0 * * * *", ""},
+	} {
+		_, err := util.ParseSchedule(tc.spec, tc.timezone)
+		assert.ErrorIs(t, err, util.ErrInvalidSchedule, tc.spec)
+	}
+}